@@ -0,0 +1,31 @@
+package auth
+
+import "strings"
+
+const (
+	RoleAdmin      = "admin"
+	RoleInstructor = "instructor"
+)
+
+// HasRole reports whether the user holds any of the given roles. The role
+// claim may carry several comma separated roles.
+func HasRole(user *JWTClaims, roles ...string) bool {
+	if user == nil {
+		return false
+	}
+	for _, held := range strings.Split(user.Role, ",") {
+		held = strings.TrimSpace(held)
+		for _, role := range roles {
+			if held == role {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsAuthor reports whether the user may see reference solutions and hidden
+// test data.
+func IsAuthor(user *JWTClaims) bool {
+	return HasRole(user, RoleAdmin, RoleInstructor)
+}
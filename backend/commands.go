@@ -0,0 +1,458 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"strathlearn/backend/config"
+	"strathlearn/backend/db"
+	"strathlearn/backend/models"
+	"strathlearn/backend/services"
+	"strathlearn/backend/services/packages"
+	"strathlearn/backend/services/runner"
+
+	"github.com/docker/docker/client"
+)
+
+// runCommand dispatches CLI subcommands and returns the process exit code.
+func runCommand(args []string) int {
+	switch args[0] {
+	case "generate-tests":
+		return generateTestsCommand(args[1:])
+	case "verify":
+		return verifyCommand(args[1:])
+	case "lint":
+		return lintCommand(args[1:])
+	case "import-challenges":
+		return importChallengesCommand(args[1:])
+	case "import-package":
+		return importPackageCommand(args[1:])
+	case "export-package":
+		return exportPackageCommand(args[1:])
+	case "migrate":
+		return migrateCommand(args[1:])
+	case "backfill-stats":
+		return backfillStatsCommand(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", args[0])
+		fmt.Fprintln(os.Stderr, "Available commands: generate-tests, verify, lint, import-challenges, import-package, export-package, migrate, backfill-stats")
+		return 2
+	}
+}
+
+// newNamedRunner builds one of the CodeRunner implementations by name.
+func newNamedRunner(name string) (runner.CodeRunner, error) {
+	switch name {
+	case "local":
+		return runner.NewLocalRunner(), nil
+	case "docker":
+		cli, err := client.NewClientWithOpts(client.FromEnv)
+		if err != nil {
+			return nil, fmt.Errorf("could not connect to Docker: %w", err)
+		}
+		return runner.NewDockerRunner(cli), nil
+	case "judge0":
+		return runner.NewJudge0Runner(), nil
+	default:
+		return nil, fmt.Errorf("unknown runner %q (want local, docker or judge0)", name)
+	}
+}
+
+func splitRoots(list string) []string {
+	var roots []string
+	for _, root := range strings.Split(list, ",") {
+		if root = strings.TrimSpace(root); root != "" {
+			roots = append(roots, root)
+		}
+	}
+	return roots
+}
+
+func generateTestsCommand(args []string) int {
+	cfg := config.Load()
+	fs := flag.NewFlagSet("generate-tests", flag.ExitOnError)
+	roots := fs.String("roots", strings.Join(cfg.ChallengeRoots, ","), "comma separated challenge directories")
+	runnerName := fs.String("runner", "local", "runner to execute programs with: local, docker or judge0")
+	only := fs.String("challenge", "", "only generate tests for this challenge ID")
+	fs.Parse(args)
+
+	r, err := newNamedRunner(*runnerName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	failed := 0
+	for id, challenge := range services.LoadChallenges(splitRoots(*roots)...) {
+		if challenge.Generator == nil || (*only != "" && id != *only) {
+			continue
+		}
+
+		testCases, err := services.GenerateTests(challenge, r)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", id, err)
+			failed++
+			continue
+		}
+
+		path, err := services.WriteGeneratedTests(challenge, testCases)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: error writing tests: %v\n", id, err)
+			failed++
+			continue
+		}
+		fmt.Printf("%s: wrote %d test cases to %s\n", id, len(testCases), path)
+	}
+
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+func verifyCommand(args []string) int {
+	cfg := config.Load()
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	roots := fs.String("roots", strings.Join(cfg.ChallengeRoots, ","), "comma separated challenge directories")
+	runnerName := fs.String("runner", "local", "runner to execute solutions with: local, docker or judge0")
+	only := fs.String("challenge", "", "only verify this challenge ID")
+	warnAt := fs.Float64("headroom", 0.5, "warn when a solution uses more than this fraction of the time limit")
+	strict := fs.Bool("strict", false, "treat time limit headroom warnings as failures")
+	fs.Parse(args)
+
+	r, err := newNamedRunner(*runnerName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	challenges := services.LoadChallenges(splitRoots(*roots)...)
+	ids := make([]string, 0, len(challenges))
+	for id := range challenges {
+		if *only == "" || id == *only {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	failed, warned := 0, 0
+	for _, id := range ids {
+		for _, report := range services.VerifyChallenge(challenges[id], r) {
+			label := fmt.Sprintf("%s solution #%d", id, report.Solution)
+			if report.Solution == 0 {
+				label = id
+			}
+
+			if !report.Passed() {
+				failed++
+				fmt.Printf("FAIL %s\n", label)
+				for _, failure := range report.Failures {
+					fmt.Printf("     %s\n", failure)
+				}
+				continue
+			}
+
+			timing := fmt.Sprintf("slowest test %.3fs", report.MaxTime)
+			if report.TimeLimit > 0 {
+				timing += fmt.Sprintf(" of %ds limit (%.0f%%)", report.TimeLimit, report.Headroom()*100)
+			}
+			if report.TimeLimit <= 0 || report.Headroom() > *warnAt {
+				warned++
+				fmt.Printf("WARN %s: %s\n", label, timing)
+				continue
+			}
+			fmt.Printf("OK   %s: %s\n", label, timing)
+		}
+	}
+
+	fmt.Printf("\n%d challenges checked, %d failing solutions, %d timing warnings\n", len(ids), failed, warned)
+	if failed > 0 || (*strict && warned > 0) {
+		return 1
+	}
+	return 0
+}
+
+func lintCommand(args []string) int {
+	cfg := config.Load()
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	roots := fs.String("roots", strings.Join(cfg.ChallengeRoots, ","), "comma separated challenge directories")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	strict := fs.Bool("strict", false, "treat warnings as errors")
+	fs.Parse(args)
+
+	report := services.LintChallenges(splitRoots(*roots)...)
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+	} else {
+		for _, issue := range report.Issues {
+			fmt.Println(issue)
+		}
+		fmt.Printf("\n%d files checked, %d errors, %d warnings\n",
+			report.Files, report.Errors(), report.Warnings())
+	}
+
+	if report.Errors() > 0 || (*strict && report.Warnings() > 0) {
+		return 1
+	}
+	return 0
+}
+
+func importChallengesCommand(args []string) int {
+	cfg := config.Load()
+	fs := flag.NewFlagSet("import-challenges", flag.ExitOnError)
+	roots := fs.String("roots", strings.Join(cfg.ChallengeRoots, ","), "comma separated challenge directories")
+	overwrite := fs.Bool("overwrite", false, "replace challenges that already exist in the database")
+	fs.Parse(args)
+
+	challenges, failed := services.LoadChallengeFiles(splitRoots(*roots)...)
+	for path, err := range failed {
+		fmt.Fprintf(os.Stderr, "Skipping %s: %v\n", path, err)
+	}
+	if len(challenges) == 0 {
+		fmt.Fprintln(os.Stderr, "No challenges found to import")
+		return 1
+	}
+
+	conn, err := db.Connect(cfg.DatabaseDriver, cfg.DatabaseURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to connect to database: %v\n", err)
+		return 1
+	}
+	defer db.Disconnect(conn)
+
+	imported, skipped, err := services.ImportChallenges(services.NewDBChallengeStore(conn, services.NewChallengeVersions(conn)), challenges, *overwrite)
+	fmt.Printf("Imported %d challenges, skipped %d existing\n", imported, skipped)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Import failed: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func importPackageCommand(args []string) int {
+	cfg := config.Load()
+	fs := flag.NewFlagSet("import-package", flag.ExitOnError)
+	format := fs.String("format", "", "package format: "+strings.Join(packages.Formats, ", "))
+	out := fs.String("out", cfg.ChallengesDir, "directory to write challenge files to")
+	overwrite := fs.Bool("overwrite", false, "replace challenge files that already exist")
+	fs.Parse(args)
+
+	if *format == "" || fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: import-package -format FORMAT [-out DIR] PACKAGE...")
+		return 2
+	}
+	if err := os.MkdirAll(*out, 0755); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	failed := 0
+	for _, pkg := range fs.Args() {
+		results, err := packages.Import(*format, pkg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", pkg, err)
+			failed++
+			continue
+		}
+
+		for _, result := range results {
+			challenge := result.Challenge
+			path := filepath.Join(*out, challenge.ID+".json")
+			if _, err := os.Stat(path); err == nil && !*overwrite {
+				fmt.Fprintf(os.Stderr, "%s: %s already exists, use -overwrite to replace it\n", challenge.ID, path)
+				failed++
+				continue
+			}
+
+			data, err := packages.MarshalChallenge(challenge)
+			if err == nil {
+				err = os.WriteFile(path, data, 0644)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s: error writing %s: %v\n", challenge.ID, path, err)
+				failed++
+				continue
+			}
+
+			fmt.Printf("%s: wrote %d test cases to %s\n", challenge.ID, len(challenge.TestCases), path)
+			for _, warning := range result.Warnings {
+				fmt.Printf("     warning: %s\n", warning)
+			}
+			_, issues := services.LintChallengeFile(path, data)
+			for _, issue := range issues {
+				fmt.Printf("     %s\n", issue)
+			}
+		}
+	}
+
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+func exportPackageCommand(args []string) int {
+	cfg := config.Load()
+	fs := flag.NewFlagSet("export-package", flag.ExitOnError)
+	roots := fs.String("roots", strings.Join(cfg.ChallengeRoots, ","), "comma separated challenge directories")
+	out := fs.String("out", "challenges.zip", "zip file to write")
+	only := fs.String("challenge", "", "comma separated challenge IDs to export (default all)")
+	fs.Parse(args)
+
+	wanted := make(map[string]bool)
+	for _, id := range splitRoots(*only) {
+		wanted[id] = true
+	}
+
+	all, _ := services.LoadChallengeFiles(splitRoots(*roots)...)
+	var challenges []models.Challenge
+	for id, challenge := range all {
+		if len(wanted) == 0 || wanted[id] {
+			challenges = append(challenges, challenge)
+			delete(wanted, id)
+		}
+	}
+	for id := range wanted {
+		fmt.Fprintf(os.Stderr, "Challenge %s not found\n", id)
+	}
+	if len(challenges) == 0 {
+		fmt.Fprintln(os.Stderr, "No challenges to export")
+		return 1
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := packages.Export(f, challenges); err != nil {
+		f.Close()
+		fmt.Fprintf(os.Stderr, "Export failed: %v\n", err)
+		return 1
+	}
+	if err := f.Close(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	fmt.Printf("Exported %d challenges to %s\n", len(challenges), *out)
+	if len(wanted) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// migrateCommand manages the database schema:
+//
+//	migrate up               apply pending migrations
+//	migrate down [-steps n]  roll back the latest n migrations
+//	migrate status           list migrations and when they were applied
+func migrateCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: migrate up|down|status")
+		return 2
+	}
+	cfg := config.Load()
+	fs := flag.NewFlagSet("migrate "+args[0], flag.ExitOnError)
+	steps := fs.Int("steps", 1, "number of migrations to roll back")
+	fs.Parse(args[1:])
+
+	conn, err := db.Open(cfg.DatabaseDriver, cfg.DatabaseURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to connect to database: %v\n", err)
+		return 1
+	}
+	defer db.Disconnect(conn)
+
+	switch args[0] {
+	case "up":
+		applied, err := db.Migrate(conn)
+		for _, m := range applied {
+			fmt.Printf("Applied %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Migration failed: %v\n", err)
+			return 1
+		}
+		if len(applied) == 0 {
+			fmt.Println("Database is up to date")
+		}
+	case "down":
+		reverted, err := db.Rollback(conn, *steps)
+		for _, m := range reverted {
+			fmt.Printf("Rolled back %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Rollback failed: %v\n", err)
+			return 1
+		}
+		if len(reverted) == 0 {
+			fmt.Println("No migrations to roll back")
+		}
+	case "status":
+		status, err := db.Status(conn)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read migration status: %v\n", err)
+			return 1
+		}
+		for _, s := range status {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = "applied " + s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%04d_%-30s %s\n", s.Version, s.Name, applied)
+		}
+		if err := db.CheckSchema(conn); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown migrate command: %s\n", args[0])
+		fmt.Fprintln(os.Stderr, "Usage: migrate up|down|status")
+		return 2
+	}
+	return 0
+}
+
+// backfillStatsCommand rebuilds profile stats from submission history.
+// Challenge difficulties come from the configured challenge store.
+func backfillStatsCommand(args []string) int {
+	cfg := config.Load()
+	fs := flag.NewFlagSet("backfill-stats", flag.ExitOnError)
+	roots := fs.String("roots", strings.Join(cfg.ChallengeRoots, ","), "comma separated challenge directories, when challenges are served from files")
+	fs.Parse(args)
+
+	conn, err := db.Connect(cfg.DatabaseDriver, cfg.DatabaseURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to connect to database: %v\n", err)
+		return 1
+	}
+	defer db.Disconnect(conn)
+
+	var challenges services.ChallengeRepository
+	if cfg.ChallengeStore == "database" {
+		challenges = services.NewDBChallengeStore(conn, services.NewChallengeVersions(conn))
+	} else {
+		loaded, failed := services.LoadChallengeFiles(splitRoots(*roots)...)
+		for path, err := range failed {
+			fmt.Fprintf(os.Stderr, "Skipping %s: %v\n", path, err)
+		}
+		challenges = services.NewFileChallengeStore(services.NewChallengeSet(loaded))
+	}
+
+	users, err := services.BackfillProfileStats(conn, challenges)
+	fmt.Printf("Rebuilt profile stats for %d users\n", users)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Backfill failed: %v\n", err)
+		return 1
+	}
+	return 0
+}
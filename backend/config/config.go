@@ -1,49 +1,119 @@
-package config
-
-import (
-	"os"
-)
-
-type Config struct {
-	Port            string
-	ChallengesDir   string
-	UseDocker       bool
-	DockerImage     string
-	AuthEnabled     bool
-	AuthProviderURL string
-	AuthCallbackURL string
-}
-
-func Load() *Config {
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
-
-	challengesDir := os.Getenv("CHALLENGES_DIR")
-	if challengesDir == "" {
-		challengesDir = "./backend/challenges"
-	}
-
-	useDocker := os.Getenv("USE_DOCKER") != "false"
-
-	dockerImage := os.Getenv("DOCKER_IMAGE")
-	if dockerImage == "" {
-		dockerImage = "strathlearn-code-runner:latest"
-	}
-
-	authEnabled := os.Getenv("AUTH_ENABLED") == "true"
-
-	authProviderURL := os.Getenv("AUTH_PROVIDER_URL")
-	authCallbackURL := os.Getenv("AUTH_CALLBACK_URL")
-
-	return &Config{
-		Port:            port,
-		ChallengesDir:   challengesDir,
-		UseDocker:       useDocker,
-		DockerImage:     dockerImage,
-		AuthEnabled:     authEnabled,
-		AuthProviderURL: authProviderURL,
-		AuthCallbackURL: authCallbackURL,
-	}
-}
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+type Config struct {
+	Port          string
+	ChallengesDir string
+	// ChallengeRoots are searched recursively for challenge files. The
+	// first root is ChallengesDir.
+	ChallengeRoots  []string
+	UseDocker       bool
+	DockerImage     string
+	AuthEnabled     bool
+	AuthProviderURL string
+	AuthCallbackURL string
+	// StrictChallenges refuses to start the server when challenge files
+	// have lint errors.
+	StrictChallenges bool
+	// WatchChallenges reloads challenges when files under the roots change.
+	WatchChallenges bool
+	// ChallengeStore selects where challenges are served from: "file" reads
+	// the challenge roots, "database" uses the challenges table and enables
+	// the authoring API.
+	ChallengeStore string
+	// RunnerWorkers is how many submissions are judged at once.
+	RunnerWorkers int
+	// RejudgeWorkers is how many of those workers a rejudge may occupy, so
+	// the rest stay free for live submissions.
+	RejudgeWorkers int
+	// DatabaseDriver is "postgres" or "sqlite". It defaults to postgres when
+	// DATABASE_URL is set and is empty, which fails to connect, when neither
+	// is set. DB_DRIVER=sqlite alone uses a local SQLite file for development.
+	DatabaseDriver string
+	DatabaseURL    string
+}
+
+func Load() *Config {
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	challengesDir := os.Getenv("CHALLENGES_DIR")
+	if challengesDir == "" {
+		challengesDir = "./backend/challenges"
+	}
+
+	challengeRoots := []string{challengesDir, "./challenges"}
+	if roots := os.Getenv("CHALLENGE_ROOTS"); roots != "" {
+		challengeRoots = []string{challengesDir}
+		for _, root := range strings.Split(roots, ",") {
+			if root = strings.TrimSpace(root); root != "" && root != challengesDir {
+				challengeRoots = append(challengeRoots, root)
+			}
+		}
+	}
+
+	useDocker := os.Getenv("USE_DOCKER") != "false"
+
+	dockerImage := os.Getenv("DOCKER_IMAGE")
+	if dockerImage == "" {
+		dockerImage = "strathlearn-code-runner:latest"
+	}
+
+	authEnabled := os.Getenv("AUTH_ENABLED") == "true"
+
+	authProviderURL := os.Getenv("AUTH_PROVIDER_URL")
+	authCallbackURL := os.Getenv("AUTH_CALLBACK_URL")
+
+	strictChallenges := os.Getenv("STRICT_CHALLENGES") == "true"
+	watchChallenges := os.Getenv("WATCH_CHALLENGES") != "false"
+
+	challengeStore := os.Getenv("CHALLENGE_STORE")
+	if challengeStore == "" {
+		challengeStore = "file"
+	}
+
+	runnerWorkers := 4
+	if n, err := strconv.Atoi(os.Getenv("RUNNER_WORKERS")); err == nil && n > 0 {
+		runnerWorkers = n
+	}
+
+	rejudgeWorkers := 1
+	if n, err := strconv.Atoi(os.Getenv("REJUDGE_WORKERS")); err == nil && n > 0 {
+		rejudgeWorkers = min(n, runnerWorkers)
+	}
+
+	databaseURL := os.Getenv("DATABASE_URL")
+	databaseDriver := os.Getenv("DB_DRIVER")
+	if databaseDriver == "" && databaseURL != "" {
+		databaseDriver = "postgres"
+	}
+	if databaseDriver == "sqlite" && databaseURL == "" {
+		databaseURL = "strathlearn.db"
+	}
+
+	return &Config{
+		Port:            port,
+		ChallengesDir:   challengesDir,
+		ChallengeRoots:  challengeRoots,
+		UseDocker:       useDocker,
+		DockerImage:     dockerImage,
+		AuthEnabled:     authEnabled,
+		AuthProviderURL: authProviderURL,
+		AuthCallbackURL: authCallbackURL,
+
+		StrictChallenges: strictChallenges,
+		WatchChallenges:  watchChallenges,
+		ChallengeStore:   challengeStore,
+		RunnerWorkers:    runnerWorkers,
+		RejudgeWorkers:   rejudgeWorkers,
+		DatabaseDriver:   databaseDriver,
+		DatabaseURL:      databaseURL,
+	}
+}
@@ -0,0 +1,127 @@
+package db
+
+import (
+	"time"
+
+	"strathlearn/backend/models"
+)
+
+type Challenge struct {
+	ID           string                        `gorm:"primaryKey"`
+	Title        string                        `gorm:"not null"`
+	Difficulty   string                        `gorm:"not null;default:''"`
+	Category     string                        `gorm:"index"`
+	Tags         []string                      `gorm:"type:text;serializer:json"`
+	Languages    []string                      `gorm:"type:text;serializer:json"`
+	Description  string                        `gorm:"type:text"`
+	Hints        []string                      `gorm:"type:text;serializer:json"`
+	HintPolicy   *models.HintPolicy            `gorm:"type:text;serializer:json"`
+	InitialCode  string                        `gorm:"type:text"`
+	Solutions    []string                      `gorm:"type:text;serializer:json"`
+	Editorial    string                        `gorm:"type:text"`
+	TimeLimit    int                           `gorm:"not null;default:1"`
+	MemoryLimit  int                           `gorm:"not null;default:128"`
+	Memcheck     bool                          `gorm:"not null;default:false"`
+	Rules        *models.CodeRules             `gorm:"type:text;serializer:json"`
+	Subtasks     []models.Subtask              `gorm:"type:text;serializer:json"`
+	Generator    *models.Generator             `gorm:"type:text;serializer:json"`
+	Translations map[string]models.Translation `gorm:"type:text;serializer:json"`
+	TestCases    []TestCase                    `gorm:"foreignKey:ChallengeID;constraint:OnDelete:CASCADE"`
+	AuthorID     string                        `gorm:"index"`
+	CreatedAt    time.Time                     `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time                     `gorm:"autoUpdateTime"`
+}
+
+type TestCase struct {
+	ID             uint   `gorm:"primaryKey"`
+	ChallengeID    string `gorm:"not null;uniqueIndex:idx_test_cases_challenge_test"`
+	TestID         string `gorm:"not null;uniqueIndex:idx_test_cases_challenge_test"`
+	Position       int    `gorm:"not null;default:0"`
+	Input          string `gorm:"type:text"`
+	ExpectedOutput string `gorm:"type:text"`
+	Hidden         bool   `gorm:"not null;default:false"`
+}
+
+func ChallengeFromModel(c models.Challenge) Challenge {
+	challenge := Challenge{
+		ID:           c.ID,
+		Title:        c.Title,
+		Difficulty:   c.Difficulty,
+		Category:     c.Category,
+		Tags:         c.Tags,
+		Languages:    c.Languages,
+		Description:  c.Description,
+		Hints:        c.Hints,
+		HintPolicy:   c.HintPolicy,
+		InitialCode:  c.InitialCode,
+		Solutions:    c.Solutions,
+		Editorial:    c.Editorial,
+		TimeLimit:    c.TimeLimit,
+		MemoryLimit:  c.MemoryLimit,
+		Memcheck:     c.Memcheck,
+		Rules:        c.Rules,
+		Subtasks:     c.Subtasks,
+		Generator:    c.Generator,
+		Translations: c.Translations,
+	}
+	for i, tc := range c.TestCases {
+		challenge.TestCases = append(challenge.TestCases, TestCaseFromModel(c.ID, i, tc))
+	}
+	return challenge
+}
+
+func TestCaseFromModel(challengeID string, position int, tc models.TestCase) TestCase {
+	return TestCase{
+		ChallengeID:    challengeID,
+		TestID:         tc.ID,
+		Position:       position,
+		Input:          tc.Input,
+		ExpectedOutput: tc.ExpectedOutput,
+		Hidden:         tc.Hidden,
+	}
+}
+
+func (c Challenge) ToModel() models.Challenge {
+	challenge := models.Challenge{
+		SchemaVersion: models.ChallengeSchemaVersion,
+		ID:            c.ID,
+		Title:         c.Title,
+		Difficulty:    c.Difficulty,
+		Category:      c.Category,
+		Tags:          c.Tags,
+		Languages:     c.Languages,
+		Description:   c.Description,
+		Hints:         c.Hints,
+		HintPolicy:    c.HintPolicy,
+		InitialCode:   c.InitialCode,
+		Solutions:     c.Solutions,
+		Editorial:     c.Editorial,
+		TimeLimit:     c.TimeLimit,
+		MemoryLimit:   c.MemoryLimit,
+		Memcheck:      c.Memcheck,
+		Rules:         c.Rules,
+		Subtasks:      c.Subtasks,
+		Generator:     c.Generator,
+		Translations:  c.Translations,
+	}
+	for _, tc := range c.TestCases {
+		challenge.TestCases = append(challenge.TestCases, models.TestCase{
+			ID:             tc.TestID,
+			Input:          tc.Input,
+			ExpectedOutput: tc.ExpectedOutput,
+			Hidden:         tc.Hidden,
+		})
+	}
+	return challenge
+}
+
+// ChallengeVersion is an immutable snapshot of a challenge. A new version is
+// recorded whenever the challenge content changes.
+type ChallengeVersion struct {
+	ID          uint             `gorm:"primaryKey"`
+	ChallengeID string           `gorm:"not null;uniqueIndex:idx_challenge_versions_challenge_version"`
+	Version     int              `gorm:"not null;uniqueIndex:idx_challenge_versions_challenge_version"`
+	Hash        string           `gorm:"not null;index"`
+	Content     models.Challenge `gorm:"type:text;serializer:json"`
+	CreatedAt   time.Time        `gorm:"autoCreateTime"`
+}
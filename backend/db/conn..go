@@ -1,33 +1,58 @@
-package db
-
-import (
-	"gorm.io/driver/postgres"
-	"gorm.io/gorm"
-)
-
-var DB *gorm.DB
-
-func Connect() (*gorm.DB, error) {
-	dsn := "host=ep-odd-mud-aau4cvww-pooler.westus3.azure.neon.tech user=neondb_owner password=npg_H20AghUkNFTY dbname=neondb port=5432 sslmode=require TimeZone=Asia/Shanghai"
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
-
-	if err != nil {
-		return nil, err
-	}
-
-	err = db.AutoMigrate(&Submission{}, &Profile{})
-	if err != nil {
-		return nil, err
-	}
-
-	DB = db
-	return db, nil
-}
-
-func Disconnect(db *gorm.DB) {
-	sqlDB, err := db.DB()
-	if err != nil {
-		panic(err)
-	}
-	sqlDB.Close()
-}
+package db
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// Open connects to a database without migrating it. The driver is
+// "postgres" or "sqlite"; for SQLite the DSN is a file path or ":memory:".
+func Open(driver, dsn string) (*gorm.DB, error) {
+	switch driver {
+	case "postgres":
+		return gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	case "sqlite":
+		// SQLite leaves foreign keys off unless every connection asks.
+		if strings.Contains(dsn, "?") {
+			dsn += "&_foreign_keys=1"
+		} else {
+			dsn += "?_foreign_keys=1"
+		}
+		return gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	case "":
+		return nil, fmt.Errorf("no database configured: set DATABASE_URL for PostgreSQL, or DB_DRIVER=sqlite for a local development database")
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q, want postgres or sqlite", driver)
+	}
+}
+
+// Connect opens the database and applies pending migrations. It refuses to
+// use a database migrated by a newer build.
+func Connect(driver, dsn string) (*gorm.DB, error) {
+	db, err := Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := Migrate(db)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range applied {
+		log.Printf("Applied migration %04d_%s", m.Version, m.Name)
+	}
+	return db, nil
+}
+
+func Disconnect(db *gorm.DB) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		panic(err)
+	}
+	sqlDB.Close()
+}
@@ -0,0 +1,12 @@
+package db
+
+import "time"
+
+// HintUnlock records that a user revealed one hint of a challenge.
+type HintUnlock struct {
+	ID          uint      `gorm:"primaryKey"`
+	UserID      string    `gorm:"not null;uniqueIndex:idx_hint_unlocks_user_challenge_hint"`
+	ChallengeID string    `gorm:"not null;uniqueIndex:idx_hint_unlocks_user_challenge_hint"`
+	HintIndex   int       `gorm:"not null;uniqueIndex:idx_hint_unlocks_user_challenge_hint"`
+	UnlockedAt  time.Time `gorm:"not null"`
+}
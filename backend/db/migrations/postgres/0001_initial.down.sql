@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS "submission_results";
+DROP TABLE IF EXISTS "solution_reveals";
+DROP TABLE IF EXISTS "give_ups";
+DROP TABLE IF EXISTS "hint_unlocks";
+DROP TABLE IF EXISTS "challenge_versions";
+DROP TABLE IF EXISTS "test_cases";
+DROP TABLE IF EXISTS "challenges";
+DROP TABLE IF EXISTS "profiles";
+DROP TABLE IF EXISTS "submissions";
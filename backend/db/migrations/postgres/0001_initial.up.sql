@@ -0,0 +1,173 @@
+-- Tables created before migrations existed are adopted: every statement is
+-- safe to run against a schema built by AutoMigrate.
+
+CREATE TABLE IF NOT EXISTS "submissions" (
+    "id" text,
+    "challenge_id" text NOT NULL,
+    "user_id" text NOT NULL,
+    "language" text NOT NULL,
+    "code" text NOT NULL,
+    "stdout" text,
+    "stderr" text,
+    "compile_output" text,
+    "message" text,
+    "status_code" bigint NOT NULL DEFAULT 0,
+    "status_desc" text NOT NULL DEFAULT 'Pending',
+    "memory" bigint NOT NULL DEFAULT 0,
+    "time" decimal NOT NULL DEFAULT 0,
+    "token" varchar(255),
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "profiles" (
+    "user_id" text,
+    "rank" bigint NOT NULL DEFAULT 0,
+    "challenges_solved" bigint NOT NULL DEFAULT 0,
+    "friends" text,
+    "friend_count" bigint NOT NULL DEFAULT 0,
+    "friend_requests" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "streaks" bigint NOT NULL DEFAULT 0,
+    "last_streak_date" timestamptz,
+    PRIMARY KEY ("user_id")
+);
+
+-- The original schema used uuid keys generated by gen_random_uuid() and
+-- text[] friend lists; convert them to the portable types.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_name = 'submissions' AND column_name = 'id' AND data_type = 'uuid') THEN
+        ALTER TABLE "submissions" ALTER COLUMN "id" DROP DEFAULT;
+        ALTER TABLE "submissions" ALTER COLUMN "id" TYPE text;
+    END IF;
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_name = 'profiles' AND column_name = 'user_id' AND data_type = 'uuid') THEN
+        ALTER TABLE "profiles" ALTER COLUMN "user_id" DROP DEFAULT;
+        ALTER TABLE "profiles" ALTER COLUMN "user_id" TYPE text;
+    END IF;
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_name = 'profiles' AND column_name = 'friends' AND data_type = 'ARRAY') THEN
+        ALTER TABLE "profiles" ALTER COLUMN "friends" TYPE text USING array_to_json("friends")::text;
+        ALTER TABLE "profiles" ALTER COLUMN "friend_requests" TYPE text USING array_to_json("friend_requests")::text;
+    END IF;
+END $$;
+
+ALTER TABLE "submissions"
+    ADD COLUMN IF NOT EXISTS "score" bigint NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS "max_score" bigint NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS "verdict" text NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS "challenge_version" bigint NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS "hints_used" bigint NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS "tests_passed" bigint NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS "tests_total" bigint NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS "idx_submissions_deleted_at" ON "submissions" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_submissions_user_id" ON "submissions" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_submissions_challenge_id" ON "submissions" ("challenge_id");
+
+ALTER TABLE "profiles"
+    ADD COLUMN IF NOT EXISTS "locale" text NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS "challenges" (
+    "id" text,
+    "title" text NOT NULL,
+    "difficulty" text NOT NULL DEFAULT '',
+    "category" text,
+    "tags" text,
+    "languages" text,
+    "description" text,
+    "hints" text,
+    "hint_policy" text,
+    "initial_code" text,
+    "solutions" text,
+    "editorial" text,
+    "time_limit" bigint NOT NULL DEFAULT 1,
+    "memory_limit" bigint NOT NULL DEFAULT 128,
+    "memcheck" boolean NOT NULL DEFAULT false,
+    "rules" text,
+    "subtasks" text,
+    "generator" text,
+    "translations" text,
+    "author_id" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_challenges_author_id" ON "challenges" ("author_id");
+CREATE INDEX IF NOT EXISTS "idx_challenges_category" ON "challenges" ("category");
+
+CREATE TABLE IF NOT EXISTS "test_cases" (
+    "id" bigserial,
+    "challenge_id" text NOT NULL,
+    "test_id" text NOT NULL,
+    "position" bigint NOT NULL DEFAULT 0,
+    "input" text,
+    "expected_output" text,
+    "hidden" boolean NOT NULL DEFAULT false,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_challenges_test_cases" FOREIGN KEY ("challenge_id") REFERENCES "challenges" ("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_test_cases_challenge_test" ON "test_cases" ("challenge_id", "test_id");
+
+CREATE TABLE IF NOT EXISTS "challenge_versions" (
+    "id" bigserial,
+    "challenge_id" text NOT NULL,
+    "version" bigint NOT NULL,
+    "hash" text NOT NULL,
+    "content" text,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_challenge_versions_hash" ON "challenge_versions" ("hash");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_challenge_versions_challenge_version" ON "challenge_versions" ("challenge_id", "version");
+
+CREATE TABLE IF NOT EXISTS "hint_unlocks" (
+    "id" bigserial,
+    "user_id" text NOT NULL,
+    "challenge_id" text NOT NULL,
+    "hint_index" bigint NOT NULL,
+    "unlocked_at" timestamptz NOT NULL,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_hint_unlocks_user_challenge_hint" ON "hint_unlocks" ("user_id", "challenge_id", "hint_index");
+
+CREATE TABLE IF NOT EXISTS "give_ups" (
+    "id" bigserial,
+    "user_id" text NOT NULL,
+    "challenge_id" text NOT NULL,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_give_ups_user_challenge" ON "give_ups" ("user_id", "challenge_id");
+
+CREATE TABLE IF NOT EXISTS "solution_reveals" (
+    "id" bigserial,
+    "user_id" text NOT NULL,
+    "challenge_id" text NOT NULL,
+    "reason" text NOT NULL,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_solution_reveals_challenge_id" ON "solution_reveals" ("challenge_id");
+CREATE INDEX IF NOT EXISTS "idx_solution_reveals_user_id" ON "solution_reveals" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "submission_results" (
+    "id" bigserial,
+    "submission_id" text NOT NULL,
+    "position" bigint NOT NULL DEFAULT 0,
+    "test_case_id" text NOT NULL,
+    "passed" boolean NOT NULL DEFAULT false,
+    "verdict" text NOT NULL DEFAULT '',
+    "output" text,
+    "error" text,
+    "execution_time" decimal NOT NULL DEFAULT 0,
+    "memory" bigint NOT NULL DEFAULT 0,
+    "memcheck" text,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_submissions_results" FOREIGN KEY ("submission_id") REFERENCES "submissions" ("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_submission_results_submission_id" ON "submission_results" ("submission_id");
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS "solves" (
+    "id" bigserial,
+    "user_id" text NOT NULL,
+    "challenge_id" text NOT NULL,
+    "difficulty" text NOT NULL DEFAULT '',
+    "submission_id" text NOT NULL,
+    "solved_at" timestamptz NOT NULL,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_solves_user_challenge" ON "solves" ("user_id", "challenge_id");
+
+ALTER TABLE "profiles"
+    ADD COLUMN IF NOT EXISTS "longest_streak" bigint NOT NULL DEFAULT 0;
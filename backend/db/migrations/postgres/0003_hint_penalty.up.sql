@@ -0,0 +1,2 @@
+ALTER TABLE "submissions"
+    ADD COLUMN IF NOT EXISTS "hint_penalty" bigint NOT NULL DEFAULT 0;
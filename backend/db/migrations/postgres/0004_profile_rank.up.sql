@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS "idx_profiles_challenges_solved" ON "profiles" ("challenges_solved");
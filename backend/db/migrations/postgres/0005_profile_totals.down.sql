@@ -0,0 +1,6 @@
+ALTER TABLE "profiles"
+    DROP COLUMN IF EXISTS "total_submissions",
+    DROP COLUMN IF EXISTS "total_score",
+    DROP COLUMN IF EXISTS "challenges_completed",
+    DROP COLUMN IF EXISTS "first_submission_at",
+    DROP COLUMN IF EXISTS "last_submission_at";
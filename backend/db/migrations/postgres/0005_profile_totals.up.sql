@@ -0,0 +1,6 @@
+ALTER TABLE "profiles"
+    ADD COLUMN IF NOT EXISTS "total_submissions" bigint NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS "total_score" bigint NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS "challenges_completed" bigint NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS "first_submission_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "last_submission_at" timestamptz;
@@ -0,0 +1,139 @@
+CREATE TABLE IF NOT EXISTS "submissions" (
+    "id" text,
+    "challenge_id" text NOT NULL,
+    "user_id" text NOT NULL,
+    "language" text NOT NULL,
+    "code" text NOT NULL,
+    "stdout" text,
+    "stderr" text,
+    "compile_output" text,
+    "message" text,
+    "status_code" integer NOT NULL DEFAULT 0,
+    "status_desc" text NOT NULL DEFAULT 'Pending',
+    "memory" integer NOT NULL DEFAULT 0,
+    "time" real NOT NULL DEFAULT 0,
+    "score" integer NOT NULL DEFAULT 0,
+    "max_score" integer NOT NULL DEFAULT 0,
+    "verdict" text NOT NULL DEFAULT '',
+    "challenge_version" integer NOT NULL DEFAULT 0,
+    "hints_used" integer NOT NULL DEFAULT 0,
+    "tests_passed" integer NOT NULL DEFAULT 0,
+    "tests_total" integer NOT NULL DEFAULT 0,
+    "token" varchar(255),
+    "created_at" datetime,
+    "updated_at" datetime,
+    "deleted_at" datetime,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_submissions_deleted_at" ON "submissions" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_submissions_user_id" ON "submissions" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_submissions_challenge_id" ON "submissions" ("challenge_id");
+
+CREATE TABLE IF NOT EXISTS "profiles" (
+    "user_id" text,
+    "rank" integer NOT NULL DEFAULT 0,
+    "challenges_solved" integer NOT NULL DEFAULT 0,
+    "friends" text,
+    "friend_count" integer NOT NULL DEFAULT 0,
+    "friend_requests" text,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "streaks" integer NOT NULL DEFAULT 0,
+    "last_streak_date" datetime,
+    "locale" text NOT NULL DEFAULT '',
+    PRIMARY KEY ("user_id")
+);
+
+CREATE TABLE IF NOT EXISTS "challenges" (
+    "id" text,
+    "title" text NOT NULL,
+    "difficulty" text NOT NULL DEFAULT '',
+    "category" text,
+    "tags" text,
+    "languages" text,
+    "description" text,
+    "hints" text,
+    "hint_policy" text,
+    "initial_code" text,
+    "solutions" text,
+    "editorial" text,
+    "time_limit" integer NOT NULL DEFAULT 1,
+    "memory_limit" integer NOT NULL DEFAULT 128,
+    "memcheck" numeric NOT NULL DEFAULT false,
+    "rules" text,
+    "subtasks" text,
+    "generator" text,
+    "translations" text,
+    "author_id" text,
+    "created_at" datetime,
+    "updated_at" datetime,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_challenges_author_id" ON "challenges" ("author_id");
+CREATE INDEX IF NOT EXISTS "idx_challenges_category" ON "challenges" ("category");
+
+CREATE TABLE IF NOT EXISTS "test_cases" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "challenge_id" text NOT NULL,
+    "test_id" text NOT NULL,
+    "position" integer NOT NULL DEFAULT 0,
+    "input" text,
+    "expected_output" text,
+    "hidden" numeric NOT NULL DEFAULT false,
+    CONSTRAINT "fk_challenges_test_cases" FOREIGN KEY ("challenge_id") REFERENCES "challenges" ("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_test_cases_challenge_test" ON "test_cases" ("challenge_id", "test_id");
+
+CREATE TABLE IF NOT EXISTS "challenge_versions" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "challenge_id" text NOT NULL,
+    "version" integer NOT NULL,
+    "hash" text NOT NULL,
+    "content" text,
+    "created_at" datetime
+);
+CREATE INDEX IF NOT EXISTS "idx_challenge_versions_hash" ON "challenge_versions" ("hash");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_challenge_versions_challenge_version" ON "challenge_versions" ("challenge_id", "version");
+
+CREATE TABLE IF NOT EXISTS "hint_unlocks" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "user_id" text NOT NULL,
+    "challenge_id" text NOT NULL,
+    "hint_index" integer NOT NULL,
+    "unlocked_at" datetime NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_hint_unlocks_user_challenge_hint" ON "hint_unlocks" ("user_id", "challenge_id", "hint_index");
+
+CREATE TABLE IF NOT EXISTS "give_ups" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "user_id" text NOT NULL,
+    "challenge_id" text NOT NULL,
+    "created_at" datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_give_ups_user_challenge" ON "give_ups" ("user_id", "challenge_id");
+
+CREATE TABLE IF NOT EXISTS "solution_reveals" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "user_id" text NOT NULL,
+    "challenge_id" text NOT NULL,
+    "reason" text NOT NULL,
+    "created_at" datetime
+);
+CREATE INDEX IF NOT EXISTS "idx_solution_reveals_challenge_id" ON "solution_reveals" ("challenge_id");
+CREATE INDEX IF NOT EXISTS "idx_solution_reveals_user_id" ON "solution_reveals" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "submission_results" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "submission_id" text NOT NULL,
+    "position" integer NOT NULL DEFAULT 0,
+    "test_case_id" text NOT NULL,
+    "passed" numeric NOT NULL DEFAULT false,
+    "verdict" text NOT NULL DEFAULT '',
+    "output" text,
+    "error" text,
+    "execution_time" real NOT NULL DEFAULT 0,
+    "memory" integer NOT NULL DEFAULT 0,
+    "memcheck" text,
+    CONSTRAINT "fk_submissions_results" FOREIGN KEY ("submission_id") REFERENCES "submissions" ("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_submission_results_submission_id" ON "submission_results" ("submission_id");
@@ -0,0 +1,2 @@
+ALTER TABLE "profiles" DROP COLUMN "longest_streak";
+DROP TABLE IF EXISTS "solves";
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS "solves" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "user_id" text NOT NULL,
+    "challenge_id" text NOT NULL,
+    "difficulty" text NOT NULL DEFAULT '',
+    "submission_id" text NOT NULL,
+    "solved_at" datetime NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_solves_user_challenge" ON "solves" ("user_id", "challenge_id");
+
+ALTER TABLE "profiles" ADD COLUMN "longest_streak" integer NOT NULL DEFAULT 0;
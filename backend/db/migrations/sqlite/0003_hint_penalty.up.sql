@@ -0,0 +1 @@
+ALTER TABLE "submissions" ADD COLUMN "hint_penalty" integer NOT NULL DEFAULT 0;
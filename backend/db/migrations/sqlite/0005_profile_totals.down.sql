@@ -0,0 +1,5 @@
+ALTER TABLE "profiles" DROP COLUMN "total_submissions";
+ALTER TABLE "profiles" DROP COLUMN "total_score";
+ALTER TABLE "profiles" DROP COLUMN "challenges_completed";
+ALTER TABLE "profiles" DROP COLUMN "first_submission_at";
+ALTER TABLE "profiles" DROP COLUMN "last_submission_at";
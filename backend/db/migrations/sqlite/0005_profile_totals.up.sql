@@ -0,0 +1,5 @@
+ALTER TABLE "profiles" ADD COLUMN "total_submissions" integer NOT NULL DEFAULT 0;
+ALTER TABLE "profiles" ADD COLUMN "total_score" integer NOT NULL DEFAULT 0;
+ALTER TABLE "profiles" ADD COLUMN "challenges_completed" integer NOT NULL DEFAULT 0;
+ALTER TABLE "profiles" ADD COLUMN "first_submission_at" datetime;
+ALTER TABLE "profiles" ADD COLUMN "last_submission_at" datetime;
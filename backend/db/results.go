@@ -0,0 +1,45 @@
+package db
+
+import "strathlearn/backend/models"
+
+// SubmissionResult is the outcome of one test case of a submission.
+type SubmissionResult struct {
+	ID            uint                   `gorm:"primaryKey"`
+	SubmissionID  string                 `gorm:"not null;index"`
+	Position      int                    `gorm:"not null;default:0"`
+	TestCaseID    string                 `gorm:"not null"`
+	Passed        bool                   `gorm:"not null;default:false"`
+	Verdict       string                 `gorm:"not null;default:''"`
+	Output        string                 `gorm:"type:text"`
+	Error         string                 `gorm:"type:text"`
+	ExecutionTime float64                `gorm:"not null;default:0"`
+	Memory        int                    `gorm:"not null;default:0"`
+	Memcheck      *models.MemcheckReport `gorm:"type:text;serializer:json"`
+}
+
+func SubmissionResultFromModel(position int, r models.TestResult) SubmissionResult {
+	return SubmissionResult{
+		Position:      position,
+		TestCaseID:    r.TestCaseID,
+		Passed:        r.Passed,
+		Verdict:       r.Verdict,
+		Output:        r.Output,
+		Error:         r.Error,
+		ExecutionTime: r.ExecutionTime,
+		Memory:        r.Memory,
+		Memcheck:      r.Memcheck,
+	}
+}
+
+func (r SubmissionResult) ToModel() models.TestResult {
+	return models.TestResult{
+		TestCaseID:    r.TestCaseID,
+		Passed:        r.Passed,
+		Verdict:       r.Verdict,
+		Output:        r.Output,
+		Error:         r.Error,
+		ExecutionTime: r.ExecutionTime,
+		Memory:        r.Memory,
+		Memcheck:      r.Memcheck,
+	}
+}
@@ -1,39 +1,68 @@
-package db
-
-import (
-	"time"
-
-	"gorm.io/gorm"
-)
-
-type Submission struct {
-	ID            string         `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
-	ChallengeID   string         `gorm:"not null;index"`
-	UserID        string         `gorm:"not null;index"`
-	Language      string         `gorm:"not null"`
-	Code          string         `gorm:"type:text;not null"`
-	Stdout        string         `gorm:"type:text"`
-	Stderr        string         `gorm:"type:text"`
-	CompileOutput string         `gorm:"type:text"`
-	Message       string         `gorm:"type:text"`
-	StatusCode    int            `gorm:"not null;default:0"`
-	StatusDesc    string         `gorm:"not null;default:'Pending'"`
-	Memory        int            `gorm:"not null;default:0"`
-	Time          float64        `gorm:"not null;default:0"`
-	Token         string         `gorm:"type:varchar(255)"`
-	CreatedAt     time.Time      `gorm:"autoCreateTime"`
-	UpdatedAt     time.Time      `gorm:"autoUpdateTime"`
-	DeletedAt     gorm.DeletedAt `gorm:"index"`
-}
-type Profile struct {
-	UserId           string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
-	Rank             int       `gorm:"not null;default:0"`
-	ChallengesSolved int       `gorm:"not null;default:0"`
-	Friends          []string  `gorm:"type:text[]"`
-	FriendCount      int       `gorm:"not null;default:0"`
-	FriendRequests   []string  `gorm:"type:text[]"`
-	CreatedAt        time.Time `gorm:"autoCreateTime"`
-	UpdatedAt        time.Time `gorm:"autoUpdateTime"`
-	Streaks          int       `gorm:"not null;default:0"`
-	LastStreakDate   time.Time `gorm:"autoCreateTime"`
-}
+package db
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Submission struct {
+	ID               string             `gorm:"primaryKey"`
+	ChallengeID      string             `gorm:"not null;index"`
+	UserID           string             `gorm:"not null;index"`
+	Language         string             `gorm:"not null"`
+	Code             string             `gorm:"type:text;not null"`
+	Stdout           string             `gorm:"type:text"`
+	Stderr           string             `gorm:"type:text"`
+	CompileOutput    string             `gorm:"type:text"`
+	Message          string             `gorm:"type:text"`
+	StatusCode       int                `gorm:"not null;default:0"`
+	StatusDesc       string             `gorm:"not null;default:'Pending'"`
+	Memory           int                `gorm:"not null;default:0"`
+	Time             float64            `gorm:"not null;default:0"`
+	Score            int                `gorm:"not null;default:0"`
+	MaxScore         int                `gorm:"not null;default:0"`
+	Verdict          string             `gorm:"not null;default:''"`
+	ChallengeVersion int                `gorm:"not null;default:0"`
+	HintsUsed        int                `gorm:"not null;default:0"`
+	HintPenalty      int                `gorm:"not null;default:0"`
+	TestsPassed      int                `gorm:"not null;default:0"`
+	TestsTotal       int                `gorm:"not null;default:0"`
+	Results          []SubmissionResult `gorm:"foreignKey:SubmissionID;constraint:OnDelete:CASCADE"`
+	Token            string             `gorm:"type:varchar(255)"`
+	CreatedAt        time.Time          `gorm:"autoCreateTime"`
+	UpdatedAt        time.Time          `gorm:"autoUpdateTime"`
+	DeletedAt        gorm.DeletedAt     `gorm:"index"`
+}
+
+// BeforeCreate assigns submission IDs in Go so that every database driver
+// gets the same UUIDs.
+func (s *Submission) BeforeCreate(*gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.NewString()
+	}
+	return nil
+}
+
+type Profile struct {
+	UserId           string    `gorm:"primaryKey"`
+	Rank             int       `gorm:"not null;default:0"`
+	ChallengesSolved int       `gorm:"not null;default:0;index"`
+	Friends          []string  `gorm:"type:text;serializer:json"`
+	FriendCount      int       `gorm:"not null;default:0"`
+	FriendRequests   []string  `gorm:"type:text;serializer:json"`
+	CreatedAt        time.Time `gorm:"autoCreateTime"`
+	UpdatedAt        time.Time `gorm:"autoUpdateTime"`
+	Streaks          int       `gorm:"not null;default:0"`
+	LongestStreak    int       `gorm:"not null;default:0"`
+	LastStreakDate   time.Time `gorm:"autoCreateTime"`
+	Locale           string    `gorm:"not null;default:''"`
+	// The submission totals below are kept in step with the submissions
+	// table so the profile page reads one row.
+	TotalSubmissions    int `gorm:"not null;default:0"`
+	TotalScore          int `gorm:"not null;default:0"`
+	ChallengesCompleted int `gorm:"not null;default:0"`
+	FirstSubmissionAt   *time.Time
+	LastSubmissionAt    *time.Time
+}
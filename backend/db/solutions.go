@@ -0,0 +1,21 @@
+package db
+
+import "time"
+
+// GiveUp records that a user gave up on a challenge to see its solutions.
+// Challenges a user gave up on never count as solved.
+type GiveUp struct {
+	ID          uint      `gorm:"primaryKey"`
+	UserID      string    `gorm:"not null;uniqueIndex:idx_give_ups_user_challenge"`
+	ChallengeID string    `gorm:"not null;uniqueIndex:idx_give_ups_user_challenge"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+}
+
+// SolutionReveal logs each time a student was shown a challenge's solutions.
+type SolutionReveal struct {
+	ID          uint      `gorm:"primaryKey"`
+	UserID      string    `gorm:"not null;index"`
+	ChallengeID string    `gorm:"not null;index"`
+	Reason      string    `gorm:"not null"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+}
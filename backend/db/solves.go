@@ -0,0 +1,15 @@
+package db
+
+import "time"
+
+// Solve records the first accepted submission of a user on a challenge.
+// Difficulty is copied from the challenge when it was solved so counts by
+// difficulty survive later edits to the challenge.
+type Solve struct {
+	ID           uint      `gorm:"primaryKey"`
+	UserID       string    `gorm:"not null;uniqueIndex:idx_solves_user_challenge"`
+	ChallengeID  string    `gorm:"not null;uniqueIndex:idx_solves_user_challenge"`
+	Difficulty   string    `gorm:"not null;default:''"`
+	SubmissionID string    `gorm:"not null"`
+	SolvedAt     time.Time `gorm:"not null"`
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"strathlearn/backend/auth"
+	"strathlearn/backend/services"
+)
+
+// ReloadChallenges re-reads the challenge files and reports what changed.
+func (h *APIHandler) ReloadChallenges(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusUnauthorized)
+		return
+	}
+	if !auth.HasRole(user, auth.RoleAdmin) {
+		http.Error(w, "Admin role required", http.StatusForbidden)
+		return
+	}
+
+	if h.reloader == nil {
+		http.Error(w, "Challenges are stored in the database and cannot be reloaded from files", http.StatusConflict)
+		return
+	}
+
+	report := h.reloader.Reload()
+
+	w.Header().Set("Content-Type", "application/json")
+	if !report.Applied {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}
+	json.NewEncoder(w).Encode(report)
+}
+
+type rejudgeRequest struct {
+	ChallengeID  string `json:"challengeId"`
+	OnlyAccepted bool   `json:"onlyAccepted"`
+}
+
+const rejudgePrefix = "/api/admin/challenges/rejudge"
+
+// RejudgeChallenge serves background rejudges:
+//
+//	POST /api/admin/challenges/rejudge          queue a rejudge of a challenge
+//	GET  /api/admin/challenges/rejudge/{jobId}  progress and report of a job
+func (h *APIHandler) RejudgeChallenge(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusUnauthorized)
+		return
+	}
+	if !auth.HasRole(user, auth.RoleAdmin) {
+		http.Error(w, "Admin role required", http.StatusForbidden)
+		return
+	}
+
+	jobID := strings.Trim(strings.TrimPrefix(r.URL.Path, rejudgePrefix), "/")
+	switch {
+	case jobID == "" && r.Method == http.MethodPost:
+		h.startRejudge(w, r)
+	case jobID != "" && r.Method == http.MethodGet:
+		job, ok := h.rejudges.Get(jobID)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(job)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *APIHandler) startRejudge(w http.ResponseWriter, r *http.Request) {
+	var req rejudgeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	challenge, ok := h.challenges.Get(req.ChallengeID)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	job, err := h.rejudges.Start(challenge, req.OnlyAccepted)
+	if errors.Is(err, services.ErrRejudgeBacklog) {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Rejudge failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", rejudgePrefix+"/"+job.ID)
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"strathlearn/backend/auth"
+	"strathlearn/backend/models"
+	"strathlearn/backend/services"
+)
+
+func TestRejudgeChallengeUpdatesProfileStats(t *testing.T) {
+	challenge := testChallenge("sum")
+	env := newTestEnv(challenge)
+	submit(t, env, student("alice"), "sum", "pass")
+	admin := &auth.JWTClaims{ID: "root", Role: auth.RoleAdmin}
+
+	w := serve(env.handler.RejudgeChallenge, http.MethodPost, rejudgePrefix, `{"challengeId":"sum"}`, student("alice"))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("student: status %d, want %d", w.Code, http.StatusForbidden)
+	}
+
+	// A new test the accepted submission fails.
+	challenge.TestCases = append(challenge.TestCases, models.TestCase{ID: "edge", Input: "0", ExpectedOutput: "unreachable"})
+	if err := env.challenges.Update(challenge); err != nil {
+		t.Fatal(err)
+	}
+	w = serve(env.handler.RejudgeChallenge, http.MethodPost, rejudgePrefix, `{"challengeId":"sum"}`, admin)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("start: status %d: %s", w.Code, w.Body.String())
+	}
+	location := w.Header().Get("Location")
+
+	var job services.RejudgeJob
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		w = serve(env.handler.RejudgeChallenge, http.MethodGet, location, "", admin)
+		decode(t, w, &job)
+		if job.Status == services.RejudgeDone || job.Status == services.RejudgeFailed {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("rejudge still %s", job.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if job.Status != services.RejudgeDone || job.Report == nil || len(job.Report.Changes) != 1 {
+		t.Fatalf("job = %+v", job)
+	}
+
+	summary, err := env.handler.stats.Summary("alice", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.ChallengesSolved != 0 || summary.ChallengesCompleted != 0 {
+		t.Errorf("stats after rejudge = %+v", summary)
+	}
+}
@@ -1,124 +1,311 @@
-package handlers
-
-import (
-	"encoding/json"
-	"fmt"
-	"log"
-	"net/http"
-	"os"
-
-	"strathlearn/backend/auth"
-	"strathlearn/backend/models"
-	"strathlearn/backend/services/runner"
-)
-
-type APIHandler struct {
-	challenges map[string]models.Challenge
-	runner     runner.CodeRunner
-}
-
-func NewAPIHandler(challenges map[string]models.Challenge, runner runner.CodeRunner) *APIHandler {
-	return &APIHandler{
-		challenges: challenges,
-		runner:     runner,
-	}
-}
-
-func (h *APIHandler) ListChallenges(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(h.challenges)
-}
-
-func (h *APIHandler) GetChallenge(w http.ResponseWriter, r *http.Request) {
-	id := r.URL.Path[len("/api/challenge/"):]
-	log.Printf("Request for challenge: %s", id)
-	if challenge, ok := h.challenges[id]; ok {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(challenge)
-	} else {
-		log.Printf("Challenge not found: %s", id)
-		http.NotFound(w, r)
-	}
-}
-
-func (h *APIHandler) SubmitSolution(w http.ResponseWriter, r *http.Request) {
-	var req models.SubmissionRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
-	}
-
-	challenge, ok := h.challenges[req.ChallengeID]
-	if !ok {
-		http.NotFound(w, r)
-		return
-	}
-
-	results := h.runner.RunTests(req.Code, challenge)
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(models.SubmissionResponse{
-		Success:     allTestsPassed(results),
-		Message:     "Submission processed",
-		TestResults: results,
-	})
-}
-
-func (h *APIHandler) Debug(w http.ResponseWriter, r *http.Request) {
-	currentDir, _ := os.Getwd()
-	fmt.Fprintf(w, "Current directory: %s\n\n", currentDir)
-
-	challengesDir := "./backend/challenges"
-	fmt.Fprintf(w, "Contents of challenges directory:\n")
-	files, err := os.ReadDir(challengesDir)
-	if err != nil {
-		fmt.Fprintf(w, "Error reading challenges dir: %v\n", err)
-	} else {
-		for _, file := range files {
-			fmt.Fprintf(w, "- %s\n", file.Name())
-		}
-	}
-
-	fmt.Fprintf(w, "\nLoaded challenges:\n")
-	for id, challenge := range h.challenges {
-		fmt.Fprintf(w, "- ID: %s, Title: %s, Source: %s\n",
-			id, challenge.Title, challenge.FilePath)
-	}
-
-	fmt.Fprintf(w, "\nDocker connection status: %v\n", h.runner.IsDockerAvailable())
-}
-
-func allTestsPassed(results []models.TestResult) bool {
-	for _, result := range results {
-		if !result.Passed {
-			return false
-		}
-	}
-	return len(results) > 0
-}
-
-func (h *APIHandler) TestAuth(w http.ResponseWriter, r *http.Request) {
-	user, ok := auth.GetUserFromContext(r)
-	if !ok {
-		http.Error(w, "User not found in context", http.StatusInternalServerError)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"message": "Authentication successful",
-		"user": map[string]interface{}{
-			"id":            user.ID,
-			"name":          user.Name,
-			"email":         user.Email,
-			"emailVerified": user.EmailVerified,
-			"image":         user.Image,
-			"createdAt":     user.CreatedAt,
-			"updatedAt":     user.UpdatedAt,
-			"role":          user.Role,
-			"banned":        user.Banned,
-			"banReason":     user.BanReason,
-			"banExpires":    user.BanExpires,
-		},
-	})
-}
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"strathlearn/backend/auth"
+	"strathlearn/backend/models"
+	"strathlearn/backend/services"
+	"strathlearn/backend/services/rules"
+	"strathlearn/backend/services/runner"
+	"strathlearn/backend/services/scoring"
+)
+
+type APIHandler struct {
+	challenges  services.ChallengeRepository
+	submissions services.SubmissionRepository
+	profiles    services.ProfileRepository
+	hints       services.HintRepository
+	solutions   services.SolutionRepository
+	stats       *services.ProfileStats
+	reloader    *services.ChallengeReloader
+	versions    services.VersionRepository
+	tracks      *services.TrackSet
+	runner      runner.CodeRunner
+	rejudges    *services.RejudgeJobs
+}
+
+func NewAPIHandler(challenges services.ChallengeRepository, submissions services.SubmissionRepository, profiles services.ProfileRepository, hints services.HintRepository, solutions services.SolutionRepository, reloader *services.ChallengeReloader, versions services.VersionRepository, tracks *services.TrackSet, runner runner.CodeRunner, rejudges *services.RejudgeJobs) *APIHandler {
+	return &APIHandler{
+		challenges:  challenges,
+		submissions: submissions,
+		profiles:    profiles,
+		hints:       hints,
+		solutions:   solutions,
+		stats:       services.NewProfileStats(profiles, submissions, challenges),
+		reloader:    reloader,
+		versions:    versions,
+		tracks:      tracks,
+		runner:      runner,
+		rejudges:    rejudges,
+	}
+}
+
+func (h *APIHandler) ListChallenges(w http.ResponseWriter, r *http.Request) {
+	preferred := h.preferredLocales(r)
+	all := h.challenges.All()
+	public := make(map[string]models.PublicChallenge, len(all))
+	for id, challenge := range all {
+		public[id] = challenge.PublicIn(preferred)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(public)
+}
+
+// ListChallengeSummaries serves the filtered, paginated challenge listing.
+// Query parameters: difficulty, tag (both repeatable or comma separated),
+// language, status (solved or unsolved), q, cursor and limit.
+func (h *APIHandler) ListChallengeSummaries(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	params := r.URL.Query()
+	query := services.ChallengeQuery{
+		Difficulties: splitParam(params["difficulty"]),
+		Tags:         splitParam(params["tag"]),
+		Language:     params.Get("language"),
+		Status:       params.Get("status"),
+		Search:       params.Get("q"),
+		Cursor:       params.Get("cursor"),
+	}
+	if query.Status != "" && query.Status != "solved" && query.Status != "unsolved" {
+		http.Error(w, "Invalid status, want solved or unsolved", http.StatusBadRequest)
+		return
+	}
+	if l := params.Get("limit"); l != "" {
+		limit, err := strconv.Atoi(l)
+		if err != nil || limit <= 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		query.Limit = limit
+	}
+
+	solved, err := h.solvedChallenges(user.ID)
+	if err != nil {
+		log.Printf("Error loading solved challenges for %s: %v", user.ID, err)
+		http.Error(w, "Failed to load progress", http.StatusInternalServerError)
+		return
+	}
+
+	page, err := services.ListChallenges(h.localizedChallenges(r), solved, query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+func splitParam(values []string) []string {
+	var parts []string
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				parts = append(parts, part)
+			}
+		}
+	}
+	return parts
+}
+
+func (h *APIHandler) GetChallenge(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Path[len("/api/challenge/"):]
+	log.Printf("Request for challenge: %s", id)
+	if challenge, ok := h.challenges.Get(id); ok {
+		w.Header().Set("Content-Type", "application/json")
+		user, _ := auth.GetUserFromContext(r)
+		if auth.IsAuthor(user) {
+			json.NewEncoder(w).Encode(challenge)
+			return
+		}
+		preferred := h.preferredLocales(r)
+		public := challenge.PublicIn(preferred)
+		if user != nil {
+			localized, _ := challenge.Localize(preferred)
+			if status, err := services.HintStatusFor(h.hints, h.submissions, user.ID, localized); err == nil {
+				for _, hint := range status.Unlocked {
+					public.Hints = append(public.Hints, hint.Text)
+				}
+			} else {
+				log.Printf("Error loading hints of %s for %s: %v", id, user.ID, err)
+			}
+		}
+		json.NewEncoder(w).Encode(public)
+	} else {
+		log.Printf("Challenge not found: %s", id)
+		http.NotFound(w, r)
+	}
+}
+
+// SubmitSolution judges a submission and records it against the caller.
+func (h *APIHandler) SubmitSolution(w http.ResponseWriter, r *http.Request) {
+	var req models.SubmissionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	challenge, ok := h.challenges.Get(req.ChallengeID)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	language, ok := requestLanguage(req.Language, challenge)
+	if !ok {
+		http.Error(w, "Language not supported by this challenge", http.StatusBadRequest)
+		return
+	}
+
+	version, err := h.versions.Current(challenge)
+	if err != nil {
+		log.Printf("Error looking up the version of challenge %s: %v", challenge.ID, err)
+	}
+
+	var results []models.TestResult
+	var score, maxScore int
+	var subtasks []models.SubtaskResult
+	message := "Submission processed"
+	if violations := rules.Check(req.Code, challenge.Rules); len(violations) > 0 {
+		_, maxScore, _ = scoring.Score(challenge, nil)
+		message = "Submission breaks the challenge rules"
+		results = []models.TestResult{{
+			TestCaseID: "rules",
+			Passed:     false,
+			Verdict:    models.VerdictRuleViolation,
+			Error:      rules.Explain(violations),
+		}}
+	} else {
+		results = h.runner.RunTests(req.Code, challenge)
+		score, maxScore, subtasks = scoring.Score(challenge, results)
+	}
+	success := allTestsPassed(results)
+
+	user, _ := auth.GetUserFromContext(r)
+	var hintsUsed, hintPenalty int
+	if user != nil {
+		if hintsUsed, err = services.HintsUsed(h.hints, user.ID, challenge); err != nil {
+			log.Printf("Error counting hints of %s for %s: %v", challenge.ID, user.ID, err)
+		}
+		if challenge.HintPolicy != nil {
+			raw := score
+			score = scoring.ApplyHintPenalty(raw, hintsUsed, challenge.HintPolicy.Penalty)
+			hintPenalty = raw - score
+		}
+	}
+
+	var submissionID string
+	var firstSolve bool
+	if user != nil {
+		submission := services.NewSubmission(user.ID, challenge.ID, language, req.Code,
+			version, results, score, maxScore, hintsUsed, hintPenalty)
+		if err := h.submissions.Create(&submission); err != nil {
+			log.Printf("Error saving submission of %s for %s: %v", challenge.ID, user.ID, err)
+		} else {
+			submissionID = submission.ID
+			if firstSolve, err = h.stats.Record(submission); err != nil {
+				log.Printf("Error updating profile stats of %s: %v", user.ID, err)
+			}
+		}
+	}
+
+	if !auth.IsAuthor(user) {
+		results = challenge.RedactResults(results)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.SubmissionResponse{
+		Success:          success,
+		Message:          message,
+		Score:            score,
+		MaxScore:         maxScore,
+		Subtasks:         subtasks,
+		TestResults:      results,
+		ChallengeVersion: version,
+		HintsUsed:        hintsUsed,
+		SubmissionID:     submissionID,
+		FirstSolve:       firstSolve,
+	})
+}
+
+func (h *APIHandler) Debug(w http.ResponseWriter, r *http.Request) {
+	currentDir, _ := os.Getwd()
+	fmt.Fprintf(w, "Current directory: %s\n\n", currentDir)
+
+	challengesDir := "./backend/challenges"
+	fmt.Fprintf(w, "Contents of challenges directory:\n")
+	files, err := os.ReadDir(challengesDir)
+	if err != nil {
+		fmt.Fprintf(w, "Error reading challenges dir: %v\n", err)
+	} else {
+		for _, file := range files {
+			fmt.Fprintf(w, "- %s\n", file.Name())
+		}
+	}
+
+	fmt.Fprintf(w, "\nLoaded challenges:\n")
+	for id, challenge := range h.challenges.All() {
+		fmt.Fprintf(w, "- ID: %s, Title: %s, Source: %s\n",
+			id, challenge.Title, challenge.FilePath)
+	}
+
+	fmt.Fprintf(w, "\nDocker connection status: %v\n", h.runner.IsDockerAvailable())
+}
+
+// requestLanguage normalizes the language a request names, defaulting to C,
+// and reports whether the challenge accepts it.
+func requestLanguage(language string, challenge models.Challenge) (string, bool) {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if language == "" {
+		language = models.DefaultLanguage
+	}
+	return language, slices.Contains(challenge.SupportedLanguages(), language)
+}
+
+func allTestsPassed(results []models.TestResult) bool {
+	for _, result := range results {
+		if !result.Passed {
+			return false
+		}
+	}
+	return len(results) > 0
+}
+
+func (h *APIHandler) TestAuth(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Authentication successful",
+		"user": map[string]interface{}{
+			"id":            user.ID,
+			"name":          user.Name,
+			"email":         user.Email,
+			"emailVerified": user.EmailVerified,
+			"image":         user.Image,
+			"createdAt":     user.CreatedAt,
+			"updatedAt":     user.UpdatedAt,
+			"role":          user.Role,
+			"banned":        user.Banned,
+			"banReason":     user.BanReason,
+			"banExpires":    user.BanExpires,
+		},
+	})
+}
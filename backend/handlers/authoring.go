@@ -0,0 +1,376 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"strathlearn/backend/auth"
+	"strathlearn/backend/models"
+	"strathlearn/backend/services"
+)
+
+const authoringPrefix = "/api/authoring/challenges"
+
+// AuthorChallenges serves the instructor authoring API:
+//
+//	GET    /api/authoring/challenges
+//	POST   /api/authoring/challenges
+//	GET    /api/authoring/challenges/{id}
+//	PUT    /api/authoring/challenges/{id}
+//	DELETE /api/authoring/challenges/{id}
+//	POST   /api/authoring/challenges/{id}/tests
+//	PUT    /api/authoring/challenges/{id}/tests/{testId}
+//	DELETE /api/authoring/challenges/{id}/tests/{testId}
+//	GET    /api/authoring/challenges/{id}/reveals
+func (h *APIHandler) AuthorChallenges(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusUnauthorized)
+		return
+	}
+	if !auth.IsAuthor(user) {
+		http.Error(w, "Instructor role required", http.StatusForbidden)
+		return
+	}
+
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, authoringPrefix), "/")
+	var parts []string
+	if rest != "" {
+		parts = strings.Split(rest, "/")
+	}
+
+	switch {
+	case len(parts) == 0 && r.Method == http.MethodGet:
+		h.listAuthoredChallenges(w)
+	case len(parts) == 0 && r.Method == http.MethodPost:
+		h.createChallenge(w, r, user.ID)
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		h.getAuthoredChallenge(w, r, parts[0])
+	case len(parts) == 1 && r.Method == http.MethodPut:
+		if h.canEdit(w, user, parts[0]) {
+			h.updateChallenge(w, r, parts[0])
+		}
+	case len(parts) == 1 && r.Method == http.MethodDelete:
+		if h.canEdit(w, user, parts[0]) {
+			h.deleteChallenge(w, parts[0])
+		}
+	case len(parts) == 2 && parts[1] == "tests" && r.Method == http.MethodPost:
+		if h.canEdit(w, user, parts[0]) {
+			h.saveTestCase(w, r, parts[0], "")
+		}
+	case len(parts) == 3 && parts[1] == "tests" && r.Method == http.MethodPut:
+		if h.canEdit(w, user, parts[0]) {
+			h.saveTestCase(w, r, parts[0], parts[2])
+		}
+	case len(parts) == 3 && parts[1] == "tests" && r.Method == http.MethodDelete:
+		if h.canEdit(w, user, parts[0]) {
+			h.deleteTestCase(w, r, parts[0], parts[2])
+		}
+	case len(parts) == 2 && parts[1] == "reveals" && r.Method == http.MethodGet:
+		h.listSolutionReveals(w, r, parts[0])
+	case len(parts) <= 3:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// canEdit reports whether user may change a challenge, writing an error
+// response when not. Instructors may only change challenges they created;
+// admins may change any, including imported ones without an author.
+func (h *APIHandler) canEdit(w http.ResponseWriter, user *auth.JWTClaims, id string) bool {
+	if auth.HasRole(user, auth.RoleAdmin) {
+		return true
+	}
+	authorID, err := h.challenges.AuthorOf(id)
+	if err != nil {
+		writeStoreError(w, err)
+		return false
+	}
+	if authorID == "" || authorID != user.ID {
+		http.Error(w, "Only the challenge's author or an admin can change it", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+func (h *APIHandler) listAuthoredChallenges(w http.ResponseWriter) {
+	all := h.challenges.All()
+	challenges := make([]models.Challenge, 0, len(all))
+	for _, challenge := range all {
+		challenges = append(challenges, challenge)
+	}
+	sort.Slice(challenges, func(i, j int) bool { return challenges[i].ID < challenges[j].ID })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(challenges)
+}
+
+func (h *APIHandler) getAuthoredChallenge(w http.ResponseWriter, r *http.Request, id string) {
+	challenge, ok := h.challenges.Get(id)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(challenge)
+}
+
+func (h *APIHandler) createChallenge(w http.ResponseWriter, r *http.Request, authorID string) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var header struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &header); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(header.ID) == "" || strings.Contains(header.ID, "/") {
+		http.Error(w, "A challenge id without slashes is required", http.StatusBadRequest)
+		return
+	}
+
+	challenge, ok := validateChallenge(w, header.ID, body)
+	if !ok {
+		return
+	}
+
+	if err := h.challenges.Create(challenge, authorID); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	log.Printf("Challenge %s created by %s", challenge.ID, authorID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(challenge)
+}
+
+func (h *APIHandler) updateChallenge(w http.ResponseWriter, r *http.Request, id string) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	challenge, ok := validateChallenge(w, id, body)
+	if !ok {
+		return
+	}
+	if challenge.ID != id {
+		http.Error(w, "Challenge id does not match the URL", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.challenges.Update(challenge); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(challenge)
+}
+
+func (h *APIHandler) deleteChallenge(w http.ResponseWriter, id string) {
+	if err := h.challenges.Delete(id); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// saveTestCase adds a test case, or replaces testID when it is set. The
+// challenge is re-validated with the change applied before it is stored.
+func (h *APIHandler) saveTestCase(w http.ResponseWriter, r *http.Request, challengeID, testID string) {
+	var tc models.TestCase
+	if err := json.NewDecoder(r.Body).Decode(&tc); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if testID != "" {
+		if tc.ID != "" && tc.ID != testID {
+			http.Error(w, "Test case id does not match the URL", http.StatusBadRequest)
+			return
+		}
+		tc.ID = testID
+	}
+	if strings.TrimSpace(tc.ID) == "" {
+		http.Error(w, "A test case id is required", http.StatusBadRequest)
+		return
+	}
+
+	challenge, ok := h.challenges.Get(challengeID)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	exists := false
+	for i := range challenge.TestCases {
+		if challenge.TestCases[i].ID == tc.ID {
+			challenge.TestCases[i] = tc
+			exists = true
+		}
+	}
+	switch {
+	case testID == "" && exists:
+		http.Error(w, "Test case already exists", http.StatusConflict)
+		return
+	case testID != "" && !exists:
+		http.NotFound(w, r)
+		return
+	case !exists:
+		challenge.TestCases = append(challenge.TestCases, tc)
+	}
+
+	data, err := json.Marshal(challenge)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, ok := validateChallenge(w, challengeID, data); !ok {
+		return
+	}
+
+	if err := h.challenges.SaveTestCase(challengeID, tc); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if testID == "" {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(tc)
+}
+
+func (h *APIHandler) deleteTestCase(w http.ResponseWriter, r *http.Request, challengeID, testID string) {
+	challenge, ok := h.challenges.Get(challengeID)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Subtasks name their tests, so removing one they still use would leave
+	// the subtask pointing at nothing.
+	var users []string
+	for _, st := range challenge.Subtasks {
+		for _, id := range st.TestCases {
+			if id == testID {
+				users = append(users, st.ID)
+				break
+			}
+		}
+	}
+	if len(users) > 0 {
+		http.Error(w, fmt.Sprintf("Test case %s is used by subtasks %s; remove it from them first",
+			testID, strings.Join(users, ", ")), http.StatusConflict)
+		return
+	}
+
+	remaining := make([]models.TestCase, 0, len(challenge.TestCases))
+	for _, tc := range challenge.TestCases {
+		if tc.ID != testID {
+			remaining = append(remaining, tc)
+		}
+	}
+	if len(remaining) == len(challenge.TestCases) {
+		http.NotFound(w, r)
+		return
+	}
+	challenge.TestCases = remaining
+
+	data, err := json.Marshal(challenge)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, ok := validateChallenge(w, challengeID, data); !ok {
+		return
+	}
+
+	if err := h.challenges.DeleteTestCase(challengeID, testID); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *APIHandler) listSolutionReveals(w http.ResponseWriter, r *http.Request, challengeID string) {
+	if _, ok := h.challenges.Get(challengeID); !ok {
+		http.NotFound(w, r)
+		return
+	}
+	reveals, err := h.solutions.Reveals(challengeID)
+	if err != nil {
+		log.Printf("Error loading solution reveals of %s: %v", challengeID, err)
+		http.Error(w, "Failed to load reveals", http.StatusInternalServerError)
+		return
+	}
+
+	type reveal struct {
+		UserID     string    `json:"userId"`
+		Reason     string    `json:"reason"`
+		RevealedAt time.Time `json:"revealedAt"`
+	}
+	entries := make([]reveal, 0, len(reveals))
+	for _, rv := range reveals {
+		entries = append(entries, reveal{UserID: rv.UserID, Reason: rv.Reason, RevealedAt: rv.CreatedAt})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"challengeId": challengeID,
+		"reveals":     entries,
+	})
+}
+
+// validateChallenge runs the challenge linter over a request body and writes
+// a 422 response listing the errors when it fails.
+func validateChallenge(w http.ResponseWriter, id string, body []byte) (models.Challenge, bool) {
+	challenge, issues := services.LintChallengeFile(id+".json", body)
+	challenge.FilePath = ""
+
+	var errs []services.LintIssue
+	for _, issue := range issues {
+		if issue.Severity == services.SeverityError {
+			errs = append(errs, issue)
+		}
+	}
+	if len(errs) > 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": "Challenge is invalid",
+			"issues":  errs,
+		})
+		return challenge, false
+	}
+	return challenge, true
+}
+
+func writeStoreError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrChallengeNotFound), errors.Is(err, services.ErrTestCaseNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrChallengeExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, services.ErrReadOnlyStore):
+		http.Error(w, "Challenges are loaded from files; set CHALLENGE_STORE=database to edit them here", http.StatusConflict)
+	default:
+		log.Printf("Challenge store error: %v", err)
+		http.Error(w, "Failed to save challenge", http.StatusInternalServerError)
+	}
+}
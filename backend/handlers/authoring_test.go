@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"strathlearn/backend/auth"
+	"strathlearn/backend/models"
+)
+
+func TestAuthorChallengesOnlyLetTheAuthorOrAnAdminEdit(t *testing.T) {
+	env := newTestEnv()
+	alice := &auth.JWTClaims{ID: "alice", Role: auth.RoleInstructor}
+	bob := &auth.JWTClaims{ID: "bob", Role: auth.RoleInstructor}
+	admin := &auth.JWTClaims{ID: "root", Role: auth.RoleAdmin}
+	if err := env.challenges.Create(testChallenge("sum"), "alice"); err != nil {
+		t.Fatal(err)
+	}
+	test := `{"id":"extra","input":"3","expectedOutput":"3"}`
+
+	w := serve(env.handler.AuthorChallenges, http.MethodPost, authoringPrefix+"/sum/tests", test, bob)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("another instructor: status %d, want %d", w.Code, http.StatusForbidden)
+	}
+	w = serve(env.handler.AuthorChallenges, http.MethodDelete, authoringPrefix+"/sum", "", bob)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("another instructor deleting: status %d, want %d", w.Code, http.StatusForbidden)
+	}
+
+	w = serve(env.handler.AuthorChallenges, http.MethodPost, authoringPrefix+"/sum/tests", test, alice)
+	if w.Code/100 != 2 {
+		t.Errorf("author: status %d: %s", w.Code, w.Body.String())
+	}
+	w = serve(env.handler.AuthorChallenges, http.MethodDelete, authoringPrefix+"/sum/tests/extra", "", admin)
+	if w.Code/100 != 2 {
+		t.Errorf("admin: status %d: %s", w.Code, w.Body.String())
+	}
+
+	w = serve(env.handler.AuthorChallenges, http.MethodGet, authoringPrefix+"/sum", "", student("eve"))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("student: status %d, want %d", w.Code, http.StatusForbidden)
+	}
+}
+
+func TestAuthorChallengesListSolutionReveals(t *testing.T) {
+	env := newTestEnv(testChallenge("sum"))
+	serve(env.handler.ChallengeSolutions, http.MethodPost, "/api/solutions/sum/give-up", "", student("alice"))
+
+	instructor := &auth.JWTClaims{ID: "carol", Role: auth.RoleInstructor}
+	w := serve(env.handler.AuthorChallenges, http.MethodGet, authoringPrefix+"/sum/reveals", "", instructor)
+	if w.Code != http.StatusOK {
+		t.Fatalf("reveals: status %d: %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Reveals []struct {
+			UserID string `json:"userId"`
+			Reason string `json:"reason"`
+		} `json:"reveals"`
+	}
+	decode(t, w, &body)
+	if len(body.Reveals) != 1 || body.Reveals[0].UserID != "alice" || body.Reveals[0].Reason != models.RevealGaveUp {
+		t.Errorf("reveals = %+v", body.Reveals)
+	}
+}
+
+func TestAuthorChallengesDeleteTestCaseKeepsTheChallengeValid(t *testing.T) {
+	env := newTestEnv()
+	admin := &auth.JWTClaims{ID: "root", Role: auth.RoleAdmin}
+	challenge := testChallenge("sum")
+	challenge.Subtasks = []models.Subtask{{ID: "basic", Points: 1, TestCases: []string{"hidden"}}}
+	if err := env.challenges.Create(challenge, "root"); err != nil {
+		t.Fatal(err)
+	}
+
+	w := serve(env.handler.AuthorChallenges, http.MethodDelete, authoringPrefix+"/sum/tests/hidden", "", admin)
+	if w.Code != http.StatusConflict {
+		t.Errorf("test used by a subtask: status %d, want %d", w.Code, http.StatusConflict)
+	}
+	w = serve(env.handler.AuthorChallenges, http.MethodDelete, authoringPrefix+"/sum/tests/missing", "", admin)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("unknown test: status %d, want %d", w.Code, http.StatusNotFound)
+	}
+
+	w = serve(env.handler.AuthorChallenges, http.MethodDelete, authoringPrefix+"/sum/tests/sample", "", admin)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("unused test: status %d: %s", w.Code, w.Body.String())
+	}
+	stored, _ := env.challenges.Get("sum")
+	if len(stored.TestCases) != 1 || stored.TestCases[0].ID != "hidden" {
+		t.Errorf("tests after delete = %+v, want only hidden", stored.TestCases)
+	}
+
+	if err := env.challenges.Create(testChallenge("single"), "root"); err != nil {
+		t.Fatal(err)
+	}
+	serve(env.handler.AuthorChallenges, http.MethodDelete, authoringPrefix+"/single/tests/sample", "", admin)
+	w = serve(env.handler.AuthorChallenges, http.MethodDelete, authoringPrefix+"/single/tests/hidden", "", admin)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("last test: status %d, want %d", w.Code, http.StatusUnprocessableEntity)
+	}
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"strathlearn/backend/auth"
+	"strathlearn/backend/models"
+	"strathlearn/backend/services"
+)
+
+var testNow = time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+
+// testRunner passes every test when the submitted code is "pass", except
+// tests expecting "unreachable", and fails every test otherwise.
+type testRunner struct{}
+
+func (testRunner) RunTests(code string, challenge models.Challenge) []models.TestResult {
+	results := make([]models.TestResult, 0, len(challenge.TestCases))
+	for _, tc := range challenge.TestCases {
+		passed := code == "pass" && tc.ExpectedOutput != "unreachable"
+		result := models.TestResult{TestCaseID: tc.ID, Passed: passed, Verdict: models.VerdictAccepted}
+		if !result.Passed {
+			result.Verdict = models.VerdictWrongAnswer
+			result.Error = "Wrong answer"
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func (testRunner) IsDockerAvailable() bool { return false }
+
+type testEnv struct {
+	handler     *APIHandler
+	challenges  *services.MemoryChallengeStore
+	submissions *services.MemorySubmissionStore
+	hints       *services.MemoryHintStore
+	solutions   *services.MemorySolutionStore
+}
+
+func newTestEnv(challenges ...models.Challenge) *testEnv {
+	versions := services.NewMemoryVersionStore()
+	env := &testEnv{
+		challenges:  services.NewMemoryChallengeStore(versions, challenges...),
+		submissions: services.NewMemorySubmissionStore(),
+		hints:       services.NewMemoryHintStore(),
+		solutions:   services.NewMemorySolutionStore(),
+	}
+	profiles := services.NewMemoryProfileStore(env.submissions)
+	rejudges := services.NewRejudgeJobs(env.submissions, testRunner{}, versions,
+		services.NewProfileStats(profiles, env.submissions, env.challenges), 1)
+	env.handler = NewAPIHandler(env.challenges, env.submissions, profiles, env.hints, env.solutions,
+		nil, versions, services.NewTrackSet(nil), testRunner{}, rejudges)
+	return env
+}
+
+func testChallenge(id string) models.Challenge {
+	return models.Challenge{
+		ID:          id,
+		Title:       "Challenge " + id,
+		Difficulty:  "easy",
+		Description: "Echo the input.",
+		TimeLimit:   1,
+		MemoryLimit: 64,
+		Hints:       []string{"first hint", "second hint"},
+		Solutions:   []string{"int main() { return 0; }"},
+		TestCases: []models.TestCase{
+			{ID: "sample", Input: "1", ExpectedOutput: "1"},
+			{ID: "hidden", Input: "2", ExpectedOutput: "2", Hidden: true},
+		},
+	}
+}
+
+func student(id string) *auth.JWTClaims {
+	return &auth.JWTClaims{ID: id, Role: "user"}
+}
+
+// serve runs handler on a request made by user, who may be nil.
+func serve(handler http.HandlerFunc, method, target, body string, user *auth.JWTClaims) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	if user != nil {
+		r = r.WithContext(context.WithValue(r.Context(), "user", user))
+	}
+	w := httptest.NewRecorder()
+	handler(w, r)
+	return w
+}
+
+func decode(t *testing.T, w *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.NewDecoder(w.Body).Decode(v); err != nil {
+		t.Fatalf("decoding response %q: %v", w.Body.String(), err)
+	}
+}
+
+func submit(t *testing.T, env *testEnv, user *auth.JWTClaims, challengeID, code string) models.SubmissionResponse {
+	t.Helper()
+	body := `{"challengeId":"` + challengeID + `","code":"` + code + `"}`
+	w := serve(env.handler.SubmitSolution, http.MethodPost, "/api/submit", body, user)
+	if w.Code != http.StatusOK {
+		t.Fatalf("submit: status %d: %s", w.Code, w.Body.String())
+	}
+	var response models.SubmissionResponse
+	decode(t, w, &response)
+	return response
+}
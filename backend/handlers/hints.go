@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"strathlearn/backend/auth"
+	"strathlearn/backend/services"
+)
+
+// ChallengeHints serves the progressive hint API:
+//
+//	GET  /api/hints/{challengeId}  hints unlocked so far
+//	POST /api/hints/{challengeId}  unlock the next hint
+func (h *APIHandler) ChallengeHints(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/hints/"), "/")
+	challenge, ok := h.challenges.Get(id)
+	if id == "" || !ok {
+		http.NotFound(w, r)
+		return
+	}
+	challenge, _ = challenge.Localize(h.preferredLocales(r))
+
+	var status services.HintStatus
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+		status, err = services.HintStatusFor(h.hints, h.submissions, user.ID, challenge)
+	case http.MethodPost:
+		status, err = services.UnlockNextHint(h.hints, h.submissions, user.ID, challenge, time.Now())
+		if err == nil {
+			log.Printf("User %s unlocked hint %d/%d of %s", user.ID, len(status.Unlocked), status.Total, id)
+		}
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	switch {
+	case errors.Is(err, services.ErrNoMoreHints):
+		writeHintError(w, http.StatusConflict, err, status)
+		return
+	case errors.Is(err, services.ErrHintNotYetOpen):
+		writeHintError(w, http.StatusTooManyRequests, err, status)
+		return
+	case err != nil:
+		log.Printf("Error loading hints of %s for %s: %v", id, user.ID, err)
+		http.Error(w, "Failed to load hints", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+func writeHintError(w http.ResponseWriter, code int, err error, status services.HintStatus) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": err.Error(),
+		"status":  status,
+	})
+}
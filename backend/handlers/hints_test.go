@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"strathlearn/backend/db"
+	"strathlearn/backend/models"
+	"strathlearn/backend/services"
+)
+
+func TestChallengeHintsUnlocksInOrder(t *testing.T) {
+	env := newTestEnv(testChallenge("sum"))
+	user := student("alice")
+
+	for want := 1; want <= 2; want++ {
+		w := serve(env.handler.ChallengeHints, http.MethodPost, "/api/hints/sum", "", user)
+		if w.Code != http.StatusOK {
+			t.Fatalf("unlock %d: status %d: %s", want, w.Code, w.Body.String())
+		}
+		var status services.HintStatus
+		decode(t, w, &status)
+		if len(status.Unlocked) != want || status.Unlocked[want-1].Index != want-1 {
+			t.Fatalf("unlock %d: unlocked %+v", want, status.Unlocked)
+		}
+	}
+
+	w := serve(env.handler.ChallengeHints, http.MethodPost, "/api/hints/sum", "", user)
+	if w.Code != http.StatusConflict {
+		t.Errorf("unlocking past the last hint: status %d, want %d", w.Code, http.StatusConflict)
+	}
+
+	w = serve(env.handler.ChallengeHints, http.MethodGet, "/api/hints/sum", "", student("bob"))
+	var status services.HintStatus
+	decode(t, w, &status)
+	if len(status.Unlocked) != 0 {
+		t.Errorf("another user sees unlocked hints %+v", status.Unlocked)
+	}
+}
+
+func TestChallengeHintsWaitForFailedAttempts(t *testing.T) {
+	challenge := testChallenge("sum")
+	challenge.HintPolicy = &models.HintPolicy{AttemptsPerHint: 1}
+	env := newTestEnv(challenge)
+	user := student("alice")
+
+	w := serve(env.handler.ChallengeHints, http.MethodPost, "/api/hints/sum", "", user)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("unlock before any attempt: status %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+
+	submit(t, env, user, "sum", "fail")
+	w = serve(env.handler.ChallengeHints, http.MethodPost, "/api/hints/sum", "", user)
+	if w.Code != http.StatusOK {
+		t.Fatalf("unlock after a failed attempt: status %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestUnlockingAHintTwiceIsANoOp(t *testing.T) {
+	hints := services.NewMemoryHintStore()
+	challenge := testChallenge("sum")
+	for i := 0; i < 2; i++ {
+		if _, err := services.UnlockNextHint(hints, services.NewMemorySubmissionStore(), "alice", challenge, testNow); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A request that read the status before the first unlock tries the
+	// same hint again.
+	if err := hints.Unlock(db.HintUnlock{UserID: "alice", ChallengeID: "sum", HintIndex: 0, UnlockedAt: testNow}); err != nil {
+		t.Fatalf("repeated unlock: %v", err)
+	}
+	if used, _ := services.HintsUsed(hints, "alice", testChallenge("sum")); used != 2 {
+		t.Errorf("hints used = %d, want 2", used)
+	}
+}
+
+func TestSubmitAppliesHintPenaltyWithoutLosingTheSolve(t *testing.T) {
+	challenge := testChallenge("sum")
+	challenge.HintPolicy = &models.HintPolicy{Penalty: 50}
+	env := newTestEnv(challenge)
+	user := student("alice")
+
+	serve(env.handler.ChallengeHints, http.MethodPost, "/api/hints/sum", "", user)
+	response := submit(t, env, user, "sum", "pass")
+	if response.HintsUsed != 1 || response.Score >= response.MaxScore {
+		t.Fatalf("score %d/%d with %d hints, want a penalty", response.Score, response.MaxScore, response.HintsUsed)
+	}
+
+	w := serve(env.handler.ChallengeSolutions, http.MethodGet, "/api/solutions/sum", "", user)
+	if w.Code != http.StatusOK {
+		t.Errorf("solutions after a hinted solve: status %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestSubmitCountsHintsWithoutAPolicy(t *testing.T) {
+	env := newTestEnv(testChallenge("sum"))
+	user := student("alice")
+
+	serve(env.handler.ChallengeHints, http.MethodPost, "/api/hints/sum", "", user)
+	response := submit(t, env, user, "sum", "pass")
+	if response.HintsUsed != 1 || response.Score != response.MaxScore {
+		t.Errorf("score %d/%d with %d hints, want full marks with 1 hint counted",
+			response.Score, response.MaxScore, response.HintsUsed)
+	}
+}
+
+func TestHintsUsedIgnoresRemovedHints(t *testing.T) {
+	hints := services.NewMemoryHintStore()
+	challenge := testChallenge("sum")
+	for i := 0; i < 2; i++ {
+		if _, err := services.UnlockNextHint(hints, services.NewMemorySubmissionStore(), "alice", challenge, testNow); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	challenge.Hints = challenge.Hints[:1]
+	if used, err := services.HintsUsed(hints, "alice", challenge); err != nil || used != 1 {
+		t.Errorf("hints used = %d, %v, want 1 after the second hint was removed", used, err)
+	}
+}
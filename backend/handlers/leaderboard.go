@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+type LeaderboardEntry struct {
+	Rank                int    `json:"rank"`
+	UserID              string `json:"userId"`
+	TotalScore          int    `json:"totalScore"`
+	ChallengesAttempted int    `json:"challengesAttempted"`
+	ChallengesCompleted int    `json:"challengesCompleted"`
+}
+
+func (h *APIHandler) GetLeaderboard(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if l := r.URL.Query().Get("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = min(parsed, 100)
+	}
+
+	rows, err := h.submissions.Leaderboard(limit)
+	if err != nil {
+		http.Error(w, "Failed to fetch leaderboard: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	entries := make([]LeaderboardEntry, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, LeaderboardEntry{
+			Rank:                row.Rank,
+			UserID:              row.UserID,
+			TotalScore:          row.TotalScore,
+			ChallengesAttempted: row.ChallengesAttempted,
+			ChallengesCompleted: row.ChallengesCompleted,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"leaderboard": entries,
+	})
+}
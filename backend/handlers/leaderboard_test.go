@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"strathlearn/backend/services"
+)
+
+func TestGetLeaderboardRanksByBestScores(t *testing.T) {
+	env := newTestEnv(testChallenge("sum"), testChallenge("product"))
+	submit(t, env, student("alice"), "sum", "pass")
+	submit(t, env, student("alice"), "sum", "fail")
+	submit(t, env, student("bob"), "sum", "pass")
+	submit(t, env, student("bob"), "product", "pass")
+	submit(t, env, student("carol"), "sum", "fail")
+
+	w := serve(env.handler.GetLeaderboard, http.MethodGet, "/api/leaderboard?limit=2", "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Leaderboard []LeaderboardEntry `json:"leaderboard"`
+	}
+	decode(t, w, &body)
+	if len(body.Leaderboard) != 2 {
+		t.Fatalf("leaderboard = %+v, want two entries", body.Leaderboard)
+	}
+	bob, alice := body.Leaderboard[0], body.Leaderboard[1]
+	if bob.UserID != "bob" || bob.Rank != 1 || bob.ChallengesCompleted != 2 {
+		t.Errorf("first entry = %+v", bob)
+	}
+	// A later failed attempt does not lower alice's best score.
+	if alice.UserID != "alice" || alice.ChallengesCompleted != 1 || alice.TotalScore != bob.TotalScore/2 {
+		t.Errorf("second entry = %+v", alice)
+	}
+}
+
+func TestGetLeaderboardRejectsBadLimits(t *testing.T) {
+	env := newTestEnv()
+	for _, limit := range []string{"0", "-1", "ten"} {
+		w := serve(env.handler.GetLeaderboard, http.MethodGet, "/api/leaderboard?limit="+limit, "", nil)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("limit %s: status %d, want %d", limit, w.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestLeaderboardAndProfileAgreeOnRanks(t *testing.T) {
+	env := newTestEnv(testChallenge("sum"), testChallenge("product"))
+	submit(t, env, student("alice"), "sum", "pass")
+	submit(t, env, student("alice"), "product", "pass")
+	submit(t, env, student("bob"), "sum", "pass")
+	submit(t, env, student("carol"), "product", "pass")
+	submit(t, env, student("dave"), "sum", "fail")
+
+	w := serve(env.handler.GetLeaderboard, http.MethodGet, "/api/leaderboard", "", nil)
+	var body struct {
+		Leaderboard []LeaderboardEntry `json:"leaderboard"`
+	}
+	decode(t, w, &body)
+	ranks := make(map[string]int)
+	for _, entry := range body.Leaderboard {
+		ranks[entry.UserID] = entry.Rank
+	}
+	// bob and carol are tied, so they share second place.
+	want := map[string]int{"alice": 1, "bob": 2, "carol": 2, "dave": 4}
+	if !reflect.DeepEqual(ranks, want) {
+		t.Errorf("leaderboard ranks = %v, want %v", ranks, want)
+	}
+
+	want["erin"] = 0
+	for userID, rank := range want {
+		w := serve(env.handler.GetUserProfile, http.MethodGet, "/api/profile", "", student(userID))
+		var profile struct {
+			Stats services.StatsSummary `json:"stats"`
+		}
+		decode(t, w, &profile)
+		if profile.Stats.Rank != rank {
+			t.Errorf("%s's profile rank = %d, want %d as on the leaderboard", userID, profile.Stats.Rank, rank)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"strathlearn/backend/auth"
+	"strathlearn/backend/models"
+)
+
+// preferredLocales lists the caller's locale preferences in priority order:
+// an explicit ?locale= parameter, the locale saved on their profile, then
+// the Accept-Language header.
+func (h *APIHandler) preferredLocales(r *http.Request) []string {
+	var locales []string
+	if locale := r.URL.Query().Get("locale"); locale != "" {
+		locales = append(locales, locale)
+	}
+	if user, ok := auth.GetUserFromContext(r); ok {
+		if locale := h.profileLocale(user.ID); locale != "" {
+			locales = append(locales, locale)
+		}
+	}
+	return append(locales, parseAcceptLanguage(r.Header.Get("Accept-Language"))...)
+}
+
+func (h *APIHandler) profileLocale(userID string) string {
+	profile, _, err := h.profiles.Get(userID)
+	if err != nil {
+		log.Printf("Error loading profile of %s: %v", userID, err)
+	}
+	return profile.Locale
+}
+
+// parseAcceptLanguage returns the languages in an Accept-Language header
+// ordered by quality, dropping any with q=0.
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var langs []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > 0 {
+			langs = append(langs, weighted{tag, q})
+		}
+	}
+	sort.SliceStable(langs, func(i, j int) bool { return langs[i].q > langs[j].q })
+
+	tags := make([]string, 0, len(langs))
+	for _, lang := range langs {
+		tags = append(tags, lang.tag)
+	}
+	return tags
+}
+
+// localizedChallenges returns every challenge in the caller's preferred
+// locale.
+func (h *APIHandler) localizedChallenges(r *http.Request) map[string]models.Challenge {
+	preferred := h.preferredLocales(r)
+	all := h.challenges.All()
+	for id, challenge := range all {
+		all[id], _ = challenge.Localize(preferred)
+	}
+	return all
+}
+
+type preferences struct {
+	Locale string `json:"locale"`
+}
+
+// UserPreferences reads or updates the current user's preferences.
+func (h *APIHandler) UserPreferences(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var req preferences
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.Locale != "" && !models.ValidLocale(req.Locale) {
+			http.Error(w, "Invalid locale", http.StatusBadRequest)
+			return
+		}
+
+		if err := h.profiles.SetLocale(user.ID, req.Locale); err != nil {
+			log.Printf("Error saving preferences for %s: %v", user.ID, err)
+			http.Error(w, "Failed to save preferences", http.StatusInternalServerError)
+			return
+		}
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(preferences{Locale: h.profileLocale(user.ID)})
+}
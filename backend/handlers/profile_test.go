@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"strathlearn/backend/services"
+)
+
+func TestGetUserProfileReadsTheProfileTotals(t *testing.T) {
+	env := newTestEnv(testChallenge("sum"), testChallenge("product"))
+	submit(t, env, student("alice"), "sum", "fail")
+	submit(t, env, student("alice"), "sum", "pass")
+	submit(t, env, student("alice"), "product", "pass")
+	submit(t, env, student("bob"), "sum", "pass")
+
+	w := serve(env.handler.GetUserProfile, http.MethodGet, "/api/profile", "", student("bob"))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Stats services.StatsSummary `json:"stats"`
+	}
+	decode(t, w, &body)
+	stats := body.Stats
+	if stats.Rank != 2 || stats.ChallengesSolved != 1 || stats.ChallengesCompleted != 1 || stats.TotalSubmissions != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if stats.SolvedByDifficulty["easy"] != 1 || stats.CurrentStreak != 1 || stats.FirstSubmission == nil {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	w = serve(env.handler.GetUserProfile, http.MethodGet, "/api/profile", "", student("carol"))
+	decode(t, w, &body)
+	if body.Stats.Rank != 0 || body.Stats.TotalSubmissions != 0 || body.Stats.FirstSubmission != nil {
+		t.Errorf("stats of a user without submissions = %+v", body.Stats)
+	}
+}
+
+func TestGetUserSubmissionsReportsProfileStreaks(t *testing.T) {
+	env := newTestEnv(testChallenge("sum"))
+	submit(t, env, student("alice"), "sum", "fail")
+	submit(t, env, student("alice"), "sum", "pass")
+
+	w := serve(env.handler.GetUserSubmissions, http.MethodGet, "/api/user/submissions", "", student("alice"))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Submissions   []DailySubmission `json:"submissions"`
+		ActiveStreak  int               `json:"activeStreak"`
+		LongestStreak int               `json:"longestStreak"`
+	}
+	decode(t, w, &body)
+	if len(body.Submissions) != 1 || body.Submissions[0].Count != 2 {
+		t.Errorf("submissions = %+v", body.Submissions)
+	}
+	if body.ActiveStreak != 1 || body.LongestStreak != 1 {
+		t.Errorf("streaks = %d, %d, want 1, 1", body.ActiveStreak, body.LongestStreak)
+	}
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"strathlearn/backend/models"
+	"strathlearn/backend/services/rules"
+)
+
+const customTestID = "custom"
+
+// RunSamples executes code against the visible sample tests of a challenge,
+// plus an optional custom input, without recording a submission. For custom
+// input the first reference solution is run to provide the expected output.
+func (h *APIHandler) RunSamples(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.RunRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	challenge, ok := h.challenges.Get(req.ChallengeID)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if _, ok := requestLanguage(req.Language, challenge); !ok {
+		http.Error(w, "Language not supported by this challenge", http.StatusBadRequest)
+		return
+	}
+
+	if violations := rules.Check(req.Code, challenge.Rules); len(violations) > 0 {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(models.RunResponse{
+			Success: false,
+			Message: "Code breaks the challenge rules",
+			TestResults: []models.TestResult{{
+				TestCaseID: "rules",
+				Passed:     false,
+				Verdict:    models.VerdictRuleViolation,
+				Error:      rules.Explain(violations),
+			}},
+		})
+		return
+	}
+
+	run := challenge
+	run.Subtasks = nil
+	run.TestCases = nil
+	for _, tc := range challenge.TestCases {
+		if !tc.Hidden {
+			run.TestCases = append(run.TestCases, tc)
+		}
+	}
+
+	var custom *models.CustomRunResult
+	haveReference := false
+	if req.Input != nil {
+		var expected string
+		expected, haveReference = h.referenceOutput(challenge, *req.Input)
+		custom = &models.CustomRunResult{Input: *req.Input, ExpectedOutput: expected}
+		run.TestCases = append(run.TestCases, models.TestCase{
+			ID:             customTestID,
+			Input:          *req.Input,
+			ExpectedOutput: expected,
+		})
+	}
+
+	results := h.runner.RunTests(req.Code, run)
+
+	samples := make([]models.TestResult, 0, len(results))
+	for _, result := range results {
+		if custom == nil || result.TestCaseID != customTestID {
+			samples = append(samples, result)
+			continue
+		}
+
+		if !haveReference && result.Verdict == models.VerdictWrongAnswer {
+			// Without a reference output there is nothing to compare
+			// against, so only execution errors are meaningful.
+			result.Passed = false
+			result.Verdict = ""
+			result.Error = ""
+		}
+		custom.Result = result
+	}
+
+	response := models.RunResponse{
+		Success:     allTestsPassed(samples),
+		Message:     "Run completed",
+		TestResults: samples,
+		Custom:      custom,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// referenceOutput runs the challenge's first reference solution on input and
+// returns what it printed.
+func (h *APIHandler) referenceOutput(challenge models.Challenge, input string) (string, bool) {
+	if len(challenge.Solutions) == 0 {
+		return "", false
+	}
+
+	reference := challenge
+	reference.Memcheck = false
+	reference.Rules = nil
+	reference.Subtasks = nil
+	reference.TestCases = []models.TestCase{{ID: customTestID, Input: input}}
+
+	results := h.runner.RunTests(challenge.Solutions[0], reference)
+	if len(results) != 1 || results[0].TestCaseID != customTestID {
+		log.Printf("Reference solution for %s failed to run on custom input", challenge.ID)
+		return "", false
+	}
+
+	result := results[0]
+	if result.Verdict != models.VerdictAccepted && result.Verdict != models.VerdictWrongAnswer {
+		log.Printf("Reference solution for %s failed on custom input: %s", challenge.ID, result.Error)
+		return "", false
+	}
+	return result.Output, true
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"strathlearn/backend/models"
+	"strathlearn/backend/services"
+)
+
+func TestRunSamplesSkipsHiddenTests(t *testing.T) {
+	env := newTestEnv(testChallenge("sum"))
+	w := serve(env.handler.RunSamples, http.MethodPost, "/api/run", `{"challengeId":"sum","code":"pass"}`, student("alice"))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+	var response models.RunResponse
+	decode(t, w, &response)
+	if len(response.TestResults) != 1 || response.TestResults[0].TestCaseID != "sample" {
+		t.Errorf("results = %+v, want only the sample", response.TestResults)
+	}
+}
+
+func TestUnsupportedLanguagesAreRejectedBeforeRunning(t *testing.T) {
+	env := newTestEnv(testChallenge("sum"))
+	body := `{"challengeId":"sum","language":"cobol","code":"pass"}`
+
+	w := serve(env.handler.RunSamples, http.MethodPost, "/api/run", body, student("alice"))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("run: status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	w = serve(env.handler.SubmitSolution, http.MethodPost, "/api/submit", body, student("alice"))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("submit: status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if page, _ := env.submissions.List(services.SubmissionQuery{UserID: "alice"}); len(page.Submissions) != 0 {
+		t.Errorf("rejected submission was recorded")
+	}
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"strathlearn/backend/auth"
+	"strathlearn/backend/models"
+)
+
+// ChallengeSolutions serves reference solutions and editorials:
+//
+//	GET  /api/solutions/{challengeId}          once solved or given up
+//	POST /api/solutions/{challengeId}/give-up  give up and reveal
+//
+// Every reveal to a student is logged; instructors read the log through the
+// authoring API.
+func (h *APIHandler) ChallengeSolutions(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	id, action, _ := strings.Cut(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/solutions/"), "/"), "/")
+	switch {
+	case action == "" && r.Method != http.MethodGet,
+		action == "give-up" && r.Method != http.MethodPost:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	case action != "" && action != "give-up":
+		http.NotFound(w, r)
+		return
+	}
+
+	challenge, ok := h.challenges.Get(id)
+	if id == "" || !ok {
+		http.NotFound(w, r)
+		return
+	}
+	challenge, locale := challenge.Localize(h.preferredLocales(r))
+	view := models.SolutionView{
+		ChallengeID: challenge.ID,
+		Solutions:   challenge.Solutions,
+		Editorial:   challenge.Editorial,
+		Locale:      locale,
+	}
+	if view.Solutions == nil {
+		view.Solutions = []string{}
+	}
+
+	if auth.IsAuthor(user) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(view)
+		return
+	}
+
+	solved, err := h.solvedChallenges(user.ID)
+	if err != nil {
+		log.Printf("Error loading solved challenges for %s: %v", user.ID, err)
+		http.Error(w, "Failed to load progress", http.StatusInternalServerError)
+		return
+	}
+	gaveUp, err := h.solutions.HasGivenUp(user.ID, id)
+	if err != nil {
+		log.Printf("Error loading give-up of %s for %s: %v", id, user.ID, err)
+		http.Error(w, "Failed to load progress", http.StatusInternalServerError)
+		return
+	}
+
+	switch {
+	case solved[id]:
+		view.Reason = models.RevealSolved
+	case gaveUp:
+		view.Reason = models.RevealGaveUp
+	case action == "give-up":
+		if err := h.solutions.GiveUp(user.ID, id); err != nil {
+			log.Printf("Error recording give-up of %s for %s: %v", id, user.ID, err)
+			http.Error(w, "Failed to give up", http.StatusInternalServerError)
+			return
+		}
+		log.Printf("User %s gave up on %s", user.ID, id)
+		view.Reason = models.RevealGaveUp
+	default:
+		http.Error(w, "Solve the challenge or give up to see its solutions", http.StatusForbidden)
+		return
+	}
+
+	if err := h.solutions.RecordReveal(user.ID, id, view.Reason); err != nil {
+		log.Printf("Error logging solution reveal of %s for %s: %v", id, user.ID, err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(view)
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"strathlearn/backend/models"
+)
+
+func TestChallengeSolutionsNeedASolveOrGiveUp(t *testing.T) {
+	env := newTestEnv(testChallenge("sum"))
+	user := student("alice")
+
+	w := serve(env.handler.ChallengeSolutions, http.MethodGet, "/api/solutions/sum", "", user)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("before solving: status %d, want %d", w.Code, http.StatusForbidden)
+	}
+
+	w = serve(env.handler.ChallengeSolutions, http.MethodPost, "/api/solutions/sum/give-up", "", user)
+	if w.Code != http.StatusOK {
+		t.Fatalf("give up: status %d: %s", w.Code, w.Body.String())
+	}
+	var view models.SolutionView
+	decode(t, w, &view)
+	if view.Reason != models.RevealGaveUp || len(view.Solutions) != 1 {
+		t.Errorf("give up view = %+v", view)
+	}
+
+	w = serve(env.handler.ChallengeSolutions, http.MethodGet, "/api/solutions/sum", "", user)
+	if w.Code != http.StatusOK {
+		t.Errorf("after giving up: status %d, want %d", w.Code, http.StatusOK)
+	}
+
+	submit(t, env, student("bob"), "sum", "pass")
+	w = serve(env.handler.ChallengeSolutions, http.MethodGet, "/api/solutions/sum", "", student("bob"))
+	decode(t, w, &view)
+	if view.Reason != models.RevealSolved {
+		t.Errorf("after solving: reason %q, want %q", view.Reason, models.RevealSolved)
+	}
+
+	reveals, err := env.solutions.Reveals("sum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reveals) != 3 || reveals[0].UserID != "bob" {
+		t.Errorf("reveals = %+v, want three, newest first", reveals)
+	}
+}
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"strathlearn/backend/auth"
+	"strathlearn/backend/db"
+	"strathlearn/backend/models"
+	"strathlearn/backend/services"
+)
+
+// SubmissionBreakdown is a past submission with the result of every test.
+type SubmissionBreakdown struct {
+	ID               string              `json:"id"`
+	ChallengeID      string              `json:"challengeId"`
+	ChallengeVersion int                 `json:"challengeVersion,omitempty"`
+	Language         string              `json:"language"`
+	Verdict          string              `json:"verdict"`
+	Score            int                 `json:"score"`
+	MaxScore         int                 `json:"maxScore"`
+	TestsPassed      int                 `json:"testsPassed"`
+	TestsTotal       int                 `json:"testsTotal"`
+	HintsUsed        int                 `json:"hintsUsed,omitempty"`
+	CreatedAt        time.Time           `json:"createdAt"`
+	TestResults      []models.TestResult `json:"testResults"`
+	Code             string              `json:"code,omitempty"`
+}
+
+// Submissions serves a user's past submissions:
+//
+//	GET /api/submissions/{id}                       code and per-test results
+//	GET /api/submissions/{id}/results               per-test results only
+//	GET /api/submissions/{id}/diff?against={other}  line diff from other to id
+func (h *APIHandler) Submissions(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusUnauthorized)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/submissions/"), "/"), "/")
+	if parts[0] == "" || len(parts) > 2 {
+		http.NotFound(w, r)
+		return
+	}
+	submission, ok := h.findSubmission(w, r, user, parts[0])
+	if !ok {
+		return
+	}
+
+	switch {
+	case len(parts) == 1:
+		h.writeBreakdown(w, user, submission, true)
+	case parts[1] == "results":
+		h.writeBreakdown(w, user, submission, false)
+	case parts[1] == "diff":
+		h.diffSubmissions(w, r, user, submission)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h *APIHandler) writeBreakdown(w http.ResponseWriter, user *auth.JWTClaims, submission db.Submission, withCode bool) {
+	results, err := h.submissions.Results(submission.ID)
+	if err != nil {
+		log.Printf("Error loading results of submission %s: %v", submission.ID, err)
+		http.Error(w, "Failed to load submission", http.StatusInternalServerError)
+		return
+	}
+	if !auth.IsAuthor(user) {
+		results = h.submittedChallenge(submission, results).RedactResults(results)
+	}
+
+	breakdown := SubmissionBreakdown{
+		ID:               submission.ID,
+		ChallengeID:      submission.ChallengeID,
+		ChallengeVersion: submission.ChallengeVersion,
+		Language:         submission.Language,
+		Verdict:          submission.Verdict,
+		Score:            submission.Score,
+		MaxScore:         submission.MaxScore,
+		TestsPassed:      submission.TestsPassed,
+		TestsTotal:       submission.TestsTotal,
+		HintsUsed:        submission.HintsUsed,
+		CreatedAt:        submission.CreatedAt,
+		TestResults:      results,
+	}
+	if withCode {
+		breakdown.Code = submission.Code
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(breakdown)
+}
+
+func (h *APIHandler) diffSubmissions(w http.ResponseWriter, r *http.Request, user *auth.JWTClaims, submission db.Submission) {
+	againstID := r.URL.Query().Get("against")
+	if againstID == "" {
+		http.Error(w, "Missing against parameter", http.StatusBadRequest)
+		return
+	}
+	against, ok := h.findSubmission(w, r, user, againstID)
+	if !ok {
+		return
+	}
+	if against.ChallengeID != submission.ChallengeID {
+		http.Error(w, "Submissions are for different challenges", http.StatusBadRequest)
+		return
+	}
+
+	lines, err := services.DiffLines(against.Code, submission.Code)
+	if errors.Is(err, services.ErrDiffTooLarge) {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		log.Printf("Error diffing submissions %s and %s: %v", against.ID, submission.ID, err)
+		http.Error(w, "Failed to diff submissions", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"challengeId": submission.ChallengeID,
+		"from":        against.ID,
+		"to":          submission.ID,
+		"lines":       lines,
+	})
+}
+
+// SubmissionHistory lists the current user's submissions, newest first:
+//
+//	GET /api/profile/submissions/history?challengeId=&verdict=&startDate=&endDate=&cursor=&limit=
+//
+// Dates are inclusive and use the same format as /api/profile/submissions.
+func (h *APIHandler) SubmissionHistory(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	params := r.URL.Query()
+	query := services.SubmissionQuery{
+		UserID:      user.ID,
+		ChallengeID: params.Get("challengeId"),
+		Verdict:     params.Get("verdict"),
+		Cursor:      params.Get("cursor"),
+	}
+	if s := params.Get("startDate"); s != "" {
+		from, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			http.Error(w, "Invalid start date format", http.StatusBadRequest)
+			return
+		}
+		query.From = from
+	}
+	if s := params.Get("endDate"); s != "" {
+		to, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			http.Error(w, "Invalid end date format", http.StatusBadRequest)
+			return
+		}
+		query.To = to.AddDate(0, 0, 1)
+	}
+	if l := params.Get("limit"); l != "" {
+		limit, err := strconv.Atoi(l)
+		if err != nil || limit <= 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		query.Limit = limit
+	}
+
+	page, err := h.submissions.List(query)
+	switch {
+	case errors.Is(err, services.ErrInvalidCursor):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		log.Printf("Error listing submissions for %s: %v", user.ID, err)
+		http.Error(w, "Failed to fetch submissions", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// findSubmission loads a submission the caller may see: their own, or any
+// submission for authors. Other users' submissions are reported as missing.
+func (h *APIHandler) findSubmission(w http.ResponseWriter, r *http.Request, user *auth.JWTClaims, id string) (db.Submission, bool) {
+	submission, found, err := h.submissions.Get(id)
+	if err != nil {
+		log.Printf("Error loading submission %s: %v", id, err)
+		http.Error(w, "Failed to load submission", http.StatusInternalServerError)
+		return submission, false
+	}
+	if !found || (submission.UserID != user.ID && !auth.IsAuthor(user)) {
+		http.NotFound(w, r)
+		return submission, false
+	}
+	return submission, true
+}
+
+// submittedChallenge returns the challenge as it was when the submission was
+// judged, so redaction follows the tests the student actually ran. When
+// neither that version nor the challenge exists any more, every test is
+// treated as hidden.
+func (h *APIHandler) submittedChallenge(submission db.Submission, results []models.TestResult) models.Challenge {
+	if challenge, ok := h.versions.Get(submission.ChallengeID, submission.ChallengeVersion); ok {
+		return challenge
+	}
+	if challenge, ok := h.challenges.Get(submission.ChallengeID); ok {
+		return challenge
+	}
+	challenge := models.Challenge{ID: submission.ChallengeID}
+	for _, r := range results {
+		challenge.TestCases = append(challenge.TestCases, models.TestCase{ID: r.TestCaseID, Hidden: true})
+	}
+	return challenge
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"strathlearn/backend/auth"
+	"strathlearn/backend/services"
+)
+
+func TestSubmissionsAreOnlyVisibleToTheirOwner(t *testing.T) {
+	env := newTestEnv(testChallenge("sum"))
+	alice := student("alice")
+	id := submit(t, env, alice, "sum", "fail").SubmissionID
+
+	w := serve(env.handler.Submissions, http.MethodGet, "/api/submissions/"+id, "", alice)
+	if w.Code != http.StatusOK {
+		t.Fatalf("owner: status %d: %s", w.Code, w.Body.String())
+	}
+	var breakdown SubmissionBreakdown
+	decode(t, w, &breakdown)
+	if breakdown.Code != "fail" || len(breakdown.TestResults) != 2 {
+		t.Errorf("breakdown = %+v", breakdown)
+	}
+	if sample := breakdown.TestResults[0]; sample.Error == "" {
+		t.Errorf("sample test lost its error: %+v", sample)
+	}
+	if hidden := breakdown.TestResults[1]; hidden.Error != "" {
+		t.Errorf("hidden test is not redacted: %+v", hidden)
+	}
+
+	w = serve(env.handler.Submissions, http.MethodGet, "/api/submissions/"+id, "", student("bob"))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("other student: status %d, want %d", w.Code, http.StatusNotFound)
+	}
+
+	instructor := &auth.JWTClaims{ID: "carol", Role: auth.RoleInstructor}
+	w = serve(env.handler.Submissions, http.MethodGet, "/api/submissions/"+id+"/results", "", instructor)
+	if w.Code != http.StatusOK {
+		t.Errorf("instructor: status %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestSubmissionDiff(t *testing.T) {
+	env := newTestEnv(testChallenge("sum"), testChallenge("product"))
+	alice := student("alice")
+	first := submit(t, env, alice, "sum", "fail").SubmissionID
+	second := submit(t, env, alice, "sum", "pass").SubmissionID
+	other := submit(t, env, alice, "product", "pass").SubmissionID
+
+	w := serve(env.handler.Submissions, http.MethodGet, "/api/submissions/"+second+"/diff?against="+first, "", alice)
+	if w.Code != http.StatusOK {
+		t.Fatalf("diff: status %d: %s", w.Code, w.Body.String())
+	}
+	var diff struct {
+		Lines []services.DiffLine `json:"lines"`
+	}
+	decode(t, w, &diff)
+	if len(diff.Lines) != 2 || diff.Lines[0].Op != services.DiffDelete || diff.Lines[1].Op != services.DiffInsert {
+		t.Errorf("diff lines = %+v", diff.Lines)
+	}
+
+	w = serve(env.handler.Submissions, http.MethodGet, "/api/submissions/"+second+"/diff?against="+other, "", alice)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("diff across challenges: status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestSubmissionsRecordTheVersionTheyWereJudgedAgainst(t *testing.T) {
+	env := newTestEnv()
+	admin := &auth.JWTClaims{ID: "root", Role: auth.RoleAdmin}
+	if err := env.challenges.Create(testChallenge("sum"), "root"); err != nil {
+		t.Fatal(err)
+	}
+	alice := student("alice")
+
+	version := func(id string) int {
+		t.Helper()
+		submission, ok, err := env.submissions.Get(id)
+		if err != nil || !ok {
+			t.Fatalf("submission %s: ok=%v err=%v", id, ok, err)
+		}
+		return submission.ChallengeVersion
+	}
+
+	first := submit(t, env, alice, "sum", "pass").SubmissionID
+	second := submit(t, env, alice, "sum", "fail").SubmissionID
+	if version(first) != 1 || version(second) != 1 {
+		t.Errorf("versions = %d, %d before any edit, want 1, 1", version(first), version(second))
+	}
+
+	test := `{"id":"extra","input":"3","expectedOutput":"3"}`
+	if w := serve(env.handler.AuthorChallenges, http.MethodPost, authoringPrefix+"/sum/tests", test, admin); w.Code/100 != 2 {
+		t.Fatalf("adding a test: status %d: %s", w.Code, w.Body.String())
+	}
+	if third := submit(t, env, alice, "sum", "pass").SubmissionID; version(third) != 2 {
+		t.Errorf("version after adding a test = %d, want 2", version(third))
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"strathlearn/backend/auth"
+	"strathlearn/backend/models"
+	"strathlearn/backend/services"
+)
+
+// solvedChallenges returns the challenges a user has earned full marks on.
+func (h *APIHandler) solvedChallenges(userID string) (map[string]bool, error) {
+	scores, err := h.submissions.BestScores(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	solved := make(map[string]bool, len(scores))
+	for _, score := range scores {
+		if score.Solved() {
+			solved[score.ChallengeID] = true
+		}
+	}
+	return solved, nil
+}
+
+// ListTracks returns every track with the current user's progress.
+func (h *APIHandler) ListTracks(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	solved, err := h.solvedChallenges(user.ID)
+	if err != nil {
+		log.Printf("Error loading solved challenges for %s: %v", user.ID, err)
+		http.Error(w, "Failed to load progress", http.StatusInternalServerError)
+		return
+	}
+
+	tracks := h.tracks.All()
+	challenges := h.localizedChallenges(r)
+	progress := make([]models.TrackProgress, 0, len(tracks))
+	for _, track := range h.tracks.Sorted() {
+		progress = append(progress, services.TrackProgress(track, tracks, challenges, solved))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"tracks": progress,
+	})
+}
+
+// GetTrack returns one track with the current user's progress.
+func (h *APIHandler) GetTrack(w http.ResponseWriter, r *http.Request) {
+	user, ok := auth.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/api/tracks/")
+	track, ok := h.tracks.Get(id)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	solved, err := h.solvedChallenges(user.ID)
+	if err != nil {
+		log.Printf("Error loading solved challenges for %s: %v", user.ID, err)
+		http.Error(w, "Failed to load progress", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(services.TrackProgress(track, h.tracks.All(), h.localizedChallenges(r), solved))
+}
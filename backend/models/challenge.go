@@ -1,42 +1,192 @@
-package models
-
-type Challenge struct {
-	ID          string     `json:"id"`
-	Title       string     `json:"title"`
-	Difficulty  string     `json:"difficulty"`
-	Description string     `json:"description"`
-	Hints       []string   `json:"hints"`
-	TestCases   []TestCase `json:"testCases"`
-	InitialCode string     `json:"initialCode"`
-	Solutions   []string   `json:"solutions"`
-	TimeLimit   int        `json:"timeLimit"`
-	MemoryLimit int        `json:"memoryLimit"`
-	FilePath    string     `json:"-"`
-}
-
-type TestCase struct {
-	ID             string `json:"id"`
-	Input          string `json:"input"`
-	ExpectedOutput string `json:"expectedOutput"`
-	Hidden         bool   `json:"hidden"`
-}
-
-type SubmissionRequest struct {
-	ChallengeID string `json:"challengeId"`
-	Code        string `json:"code"`
-}
-
-type TestResult struct {
-	TestCaseID    string  `json:"testCaseId"` // Frontend expects camelCase
-	Passed        bool    `json:"passed"`
-	Output        string  `json:"output,omitempty"`
-	Error         string  `json:"error,omitempty"`
-	ExecutionTime float64 `json:"executionTime,omitempty"`
-	Memory        int     `json:"memory,omitempty"`
-}
-
-type SubmissionResponse struct {
-	Success     bool         `json:"success"`
-	Message     string       `json:"message"`
-	TestResults []TestResult `json:"testResults"`
-}
+package models
+
+// DefaultLanguage is the language of challenges that do not list any; every
+// runner compiles submissions as C.
+const DefaultLanguage = "c"
+
+// ChallengeSchemaVersion is the newest challenge file format this build
+// understands. Files without a schemaVersion are treated as version 1.
+const ChallengeSchemaVersion = 1
+
+type Challenge struct {
+	SchemaVersion int         `json:"schemaVersion,omitempty"`
+	ID            string      `json:"id"`
+	Title         string      `json:"title"`
+	Difficulty    string      `json:"difficulty"`
+	Category      string      `json:"category,omitempty"`
+	Tags          []string    `json:"tags,omitempty"`
+	Languages     []string    `json:"languages,omitempty"`
+	Description   string      `json:"description"`
+	Hints         []string    `json:"hints"`
+	HintPolicy    *HintPolicy `json:"hintPolicy,omitempty"`
+	TestCases     []TestCase  `json:"testCases"`
+	InitialCode   string      `json:"initialCode"`
+	Solutions     []string    `json:"solutions"`
+	Editorial     string      `json:"editorial,omitempty"`
+	TimeLimit     int         `json:"timeLimit"`
+	MemoryLimit   int         `json:"memoryLimit"`
+	Memcheck      bool        `json:"memcheck,omitempty"`
+	Rules         *CodeRules  `json:"rules,omitempty"`
+	Subtasks      []Subtask   `json:"subtasks,omitempty"`
+	Generator     *Generator  `json:"generator,omitempty"`
+	// Translations are keyed by BCP 47 language tag such as "sw" or
+	// "sw-KE". The untranslated fields are in DefaultLocale.
+	Translations map[string]Translation `json:"translations,omitempty"`
+	FilePath     string                 `json:"-"`
+}
+
+// HintPolicy controls how hints are revealed. Hints are always revealed one
+// at a time; without a policy there is no wait and no score penalty.
+type HintPolicy struct {
+	// AttemptsPerHint is how many failed submissions are needed before each
+	// further hint unlocks.
+	AttemptsPerHint int `json:"attemptsPerHint,omitempty"`
+	// CooldownSeconds is the minimum time between two hint unlocks.
+	CooldownSeconds int `json:"cooldownSeconds,omitempty"`
+	// Penalty is the percentage of the score deducted per hint used.
+	Penalty int `json:"penalty,omitempty"`
+}
+
+// Translation overrides the student-facing text of a challenge. Fields left
+// empty fall back to the default locale.
+type Translation struct {
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Hints       []string `json:"hints,omitempty"`
+	InitialCode string   `json:"initialCode,omitempty"`
+	Editorial   string   `json:"editorial,omitempty"`
+}
+
+// SupportedLanguages returns the languages a challenge accepts.
+func (c Challenge) SupportedLanguages() []string {
+	if len(c.Languages) == 0 {
+		return []string{DefaultLanguage}
+	}
+	return c.Languages
+}
+
+type TestCase struct {
+	ID             string `json:"id"`
+	Input          string `json:"input"`
+	ExpectedOutput string `json:"expectedOutput"`
+	Hidden         bool   `json:"hidden"`
+}
+
+// Subtask groups test cases that are scored together. A subtask earns its
+// points only if every one of its tests passes and every subtask it depends
+// on earned its points too.
+type Subtask struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name,omitempty"`
+	Points    int      `json:"points"`
+	TestCases []string `json:"testCases"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+// Generator describes a program that produces test inputs. It reads a seed
+// on stdin and prints one test input; expected outputs come from running the
+// first reference solution on that input.
+type Generator struct {
+	Source string   `json:"source,omitempty"`
+	File   string   `json:"file,omitempty"`
+	Seeds  []string `json:"seeds"`
+	Hidden bool     `json:"hidden"`
+}
+
+// CodeRules restricts what a submission may contain. They are checked on the
+// source before it is compiled.
+type CodeRules struct {
+	BannedIdentifiers  []string `json:"bannedIdentifiers,omitempty"`
+	BannedHeaders      []string `json:"bannedHeaders,omitempty"`
+	RequiredConstructs []string `json:"requiredConstructs,omitempty"`
+}
+
+type SubmissionRequest struct {
+	ChallengeID string `json:"challengeId"`
+	Language    string `json:"language,omitempty"`
+	Code        string `json:"code"`
+}
+
+const (
+	VerdictAccepted      = "Accepted"
+	VerdictWrongAnswer   = "Wrong answer"
+	VerdictTimeLimit     = "Time limit exceeded"
+	VerdictRuntimeError  = "Runtime error"
+	VerdictCompileError  = "Compilation error"
+	VerdictMemoryError   = "Memory error"
+	VerdictSystemError   = "System error"
+	VerdictRuleViolation = "Rule violation"
+	VerdictSkipped       = "Skipped"
+)
+
+type TestResult struct {
+	TestCaseID    string          `json:"testCaseId"` // Frontend expects camelCase
+	Passed        bool            `json:"passed"`
+	Verdict       string          `json:"verdict,omitempty"`
+	Output        string          `json:"output,omitempty"`
+	Error         string          `json:"error,omitempty"`
+	ExecutionTime float64         `json:"executionTime,omitempty"`
+	Memory        int             `json:"memory,omitempty"`
+	Memcheck      *MemcheckReport `json:"memcheck,omitempty"`
+}
+
+// MemcheckReport summarises the Valgrind findings for a single test case.
+type MemcheckReport struct {
+	LeakedBytes   int             `json:"leakedBytes"`
+	LeakedBlocks  int             `json:"leakedBlocks"`
+	InvalidReads  int             `json:"invalidReads"`
+	InvalidWrites int             `json:"invalidWrites"`
+	InvalidFrees  int             `json:"invalidFrees"`
+	Uninitialised int             `json:"uninitialised"`
+	Errors        []MemcheckError `json:"errors,omitempty"`
+}
+
+type MemcheckError struct {
+	Kind        string `json:"kind"`
+	What        string `json:"what"`
+	File        string `json:"file,omitempty"`
+	Line        int    `json:"line,omitempty"`
+	LeakedBytes int    `json:"leakedBytes,omitempty"`
+}
+
+// RunRequest executes code against a challenge's sample tests, and
+// optionally a custom input, without recording a submission.
+type RunRequest struct {
+	ChallengeID string  `json:"challengeId"`
+	Language    string  `json:"language,omitempty"`
+	Code        string  `json:"code"`
+	Input       *string `json:"input,omitempty"`
+}
+
+type CustomRunResult struct {
+	Input          string     `json:"input"`
+	ExpectedOutput string     `json:"expectedOutput,omitempty"`
+	Result         TestResult `json:"result"`
+}
+
+type RunResponse struct {
+	Success     bool             `json:"success"`
+	Message     string           `json:"message"`
+	TestResults []TestResult     `json:"testResults"`
+	Custom      *CustomRunResult `json:"custom,omitempty"`
+}
+
+type SubtaskResult struct {
+	ID     string `json:"id"`
+	Name   string `json:"name,omitempty"`
+	Points int    `json:"points"`
+	Earned int    `json:"earned"`
+}
+
+type SubmissionResponse struct {
+	Success          bool            `json:"success"`
+	Message          string          `json:"message"`
+	Score            int             `json:"score"`
+	MaxScore         int             `json:"maxScore"`
+	Subtasks         []SubtaskResult `json:"subtasks,omitempty"`
+	TestResults      []TestResult    `json:"testResults"`
+	ChallengeVersion int             `json:"challengeVersion,omitempty"`
+	HintsUsed        int             `json:"hintsUsed,omitempty"`
+	SubmissionID     string          `json:"submissionId,omitempty"`
+	FirstSolve       bool            `json:"firstSolve,omitempty"`
+}
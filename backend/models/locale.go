@@ -0,0 +1,99 @@
+package models
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultLocale is the language of a challenge's untranslated fields.
+const DefaultLocale = "en"
+
+var localeTag = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// ValidLocale reports whether s looks like a language tag such as "sw" or
+// "sw-KE".
+func ValidLocale(s string) bool {
+	return localeTag.MatchString(s)
+}
+
+// Locales lists the locales a challenge is available in, default first.
+func (c Challenge) Locales() []string {
+	locales := make([]string, 0, len(c.Translations))
+	for locale := range c.Translations {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return append([]string{DefaultLocale}, locales...)
+}
+
+// MatchLocale picks the best available locale for a list of preferences in
+// priority order. "sw-KE" matches a "sw-KE" translation first, then "sw",
+// then any other "sw-" variant.
+func (c Challenge) MatchLocale(preferred []string) string {
+	for _, want := range preferred {
+		want = strings.ToLower(strings.TrimSpace(want))
+		if want == "" || want == "*" {
+			continue
+		}
+		base, _, _ := strings.Cut(want, "-")
+		if base == DefaultLocale {
+			return DefaultLocale
+		}
+
+		var baseMatch, variantMatch string
+		for locale := range c.Translations {
+			have := strings.ToLower(locale)
+			haveBase, _, _ := strings.Cut(have, "-")
+			switch {
+			case have == want:
+				return locale
+			case have == base:
+				baseMatch = locale
+			case haveBase == base && (variantMatch == "" || locale < variantMatch):
+				variantMatch = locale
+			}
+		}
+		if baseMatch != "" {
+			return baseMatch
+		}
+		if variantMatch != "" {
+			return variantMatch
+		}
+	}
+	return DefaultLocale
+}
+
+// Localize returns the challenge with its student-facing text in the best
+// matching locale, and the locale chosen.
+func (c Challenge) Localize(preferred []string) (Challenge, string) {
+	locale := c.MatchLocale(preferred)
+	t, ok := c.Translations[locale]
+	if !ok {
+		return c, DefaultLocale
+	}
+	if t.Title != "" {
+		c.Title = t.Title
+	}
+	if t.Description != "" {
+		c.Description = t.Description
+	}
+	if len(t.Hints) > 0 {
+		// Hints are unlocked by index, so a translation replaces them one
+		// by one and never changes how many there are.
+		hints := append([]string(nil), c.Hints...)
+		for i := range hints {
+			if i < len(t.Hints) && t.Hints[i] != "" {
+				hints[i] = t.Hints[i]
+			}
+		}
+		c.Hints = hints
+	}
+	if t.InitialCode != "" {
+		c.InitialCode = t.InitialCode
+	}
+	if t.Editorial != "" {
+		c.Editorial = t.Editorial
+	}
+	return c, locale
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidLocale(t *testing.T) {
+	for locale, want := range map[string]bool{
+		"sw":         true,
+		"sw-KE":      true,
+		"zh-Hant-TW": true,
+		"fil":        true,
+		"":           false,
+		"s":          false,
+		"swahili":    false,
+		"sw_KE":      false,
+		"sw-":        false,
+		"../etc":     false,
+	} {
+		if got := ValidLocale(locale); got != want {
+			t.Errorf("ValidLocale(%q) = %v, want %v", locale, got, want)
+		}
+	}
+}
+
+func translatedChallenge() Challenge {
+	return Challenge{
+		ID:          "hello",
+		Title:       "Hello",
+		Description: "Print a greeting.",
+		Hints:       []string{"Use printf", "End with a newline"},
+		InitialCode: "int main() {}",
+		Editorial:   "Call printf once.",
+		Translations: map[string]Translation{
+			"sw": {
+				Title:       "Habari",
+				Description: "Chapisha salamu.",
+				Hints:       []string{"Tumia printf", "Maliza kwa mstari mpya"},
+			},
+			"sw-TZ": {Title: "Habari (TZ)"},
+			"fr-CA": {Title: "Bonjour (CA)"},
+			"fr-BE": {Title: "Bonjour (BE)"},
+		},
+	}
+}
+
+func TestLocales(t *testing.T) {
+	want := []string{"en", "fr-BE", "fr-CA", "sw", "sw-TZ"}
+	if got := translatedChallenge().Locales(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Locales = %v, want %v", got, want)
+	}
+	if got := (Challenge{}).Locales(); !reflect.DeepEqual(got, []string{"en"}) {
+		t.Errorf("untranslated Locales = %v, want [en]", got)
+	}
+}
+
+func TestMatchLocale(t *testing.T) {
+	tests := []struct {
+		preferred []string
+		want      string
+	}{
+		{nil, "en"},
+		{[]string{"sw"}, "sw"},
+		{[]string{"SW-tz"}, "sw-TZ"},
+		{[]string{"sw-KE"}, "sw"},
+		{[]string{"fr"}, "fr-BE"},
+		{[]string{"fr-FR"}, "fr-BE"},
+		{[]string{"de", "*", "sw-TZ"}, "sw-TZ"},
+		{[]string{"en-GB", "sw"}, "en"},
+		{[]string{"de", "it"}, "en"},
+		{[]string{" ", "fr-CA"}, "fr-CA"},
+	}
+	challenge := translatedChallenge()
+	for _, tt := range tests {
+		if got := challenge.MatchLocale(tt.preferred); got != tt.want {
+			t.Errorf("MatchLocale(%q) = %q, want %q", tt.preferred, got, tt.want)
+		}
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	challenge := translatedChallenge()
+
+	localized, locale := challenge.Localize([]string{"sw"})
+	if locale != "sw" {
+		t.Fatalf("locale = %q, want sw", locale)
+	}
+	if localized.Title != "Habari" || localized.Description != "Chapisha salamu." {
+		t.Errorf("title, description = %q, %q", localized.Title, localized.Description)
+	}
+	if want := []string{"Tumia printf", "Maliza kwa mstari mpya"}; !reflect.DeepEqual(localized.Hints, want) {
+		t.Errorf("hints = %q, want %q", localized.Hints, want)
+	}
+	// Untranslated fields fall back to the default text.
+	if localized.InitialCode != challenge.InitialCode || localized.Editorial != challenge.Editorial {
+		t.Errorf("initial code, editorial = %q, %q, want the untranslated ones", localized.InitialCode, localized.Editorial)
+	}
+	if challenge.Title != "Hello" {
+		t.Error("Localize changed the original challenge")
+	}
+
+	partial, locale := challenge.Localize([]string{"sw-TZ"})
+	if locale != "sw-TZ" || partial.Title != "Habari (TZ)" || partial.Description != challenge.Description {
+		t.Errorf("partial translation = %q, %q in %s", partial.Title, partial.Description, locale)
+	}
+	if !reflect.DeepEqual(partial.Hints, challenge.Hints) {
+		t.Errorf("hints = %q, want the untranslated hints", partial.Hints)
+	}
+
+	challenge.Translations["sw-TZ"] = Translation{Hints: []string{"", "Maliza kwa mstari mpya", "Ziada"}}
+	merged, _ := challenge.Localize([]string{"sw-TZ"})
+	if want := []string{"Use printf", "Maliza kwa mstari mpya"}; !reflect.DeepEqual(merged.Hints, want) {
+		t.Errorf("hints = %q, want %q: translated per index and never more than the original", merged.Hints, want)
+	}
+	if challenge.Hints[1] != "End with a newline" {
+		t.Error("Localize changed the original hints")
+	}
+
+	same, locale := challenge.Localize([]string{"de"})
+	if locale != DefaultLocale || !reflect.DeepEqual(same, challenge) {
+		t.Errorf("unmatched locale returned %q and a changed challenge", locale)
+	}
+}
@@ -0,0 +1,61 @@
+package models
+
+// Track is an ordered curriculum made of modules, each listing challenges in
+// the order they should be attempted.
+type Track struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	// Order positions the track in listings; ties are broken by ID.
+	Order int `json:"order,omitempty"`
+	// Prerequisites are tracks that must be completed before this one
+	// unlocks.
+	Prerequisites []string `json:"prerequisites,omitempty"`
+	// Sequential makes every module require the one before it.
+	Sequential bool     `json:"sequential,omitempty"`
+	Modules    []Module `json:"modules"`
+	FilePath   string   `json:"-"`
+}
+
+// Module unlocks once its track is unlocked and its prerequisite modules are
+// complete. It is complete when RequiredSolved of its challenges are solved,
+// or all of them when RequiredSolved is zero.
+type Module struct {
+	ID             string   `json:"id"`
+	Title          string   `json:"title"`
+	Description    string   `json:"description,omitempty"`
+	Challenges     []string `json:"challenges"`
+	Prerequisites  []string `json:"prerequisites,omitempty"`
+	RequiredSolved int      `json:"requiredSolved,omitempty"`
+}
+
+type TrackProgress struct {
+	ID            string           `json:"id"`
+	Title         string           `json:"title"`
+	Description   string           `json:"description,omitempty"`
+	Prerequisites []string         `json:"prerequisites,omitempty"`
+	Unlocked      bool             `json:"unlocked"`
+	Completed     bool             `json:"completed"`
+	Solved        int              `json:"solved"`
+	Total         int              `json:"total"`
+	Modules       []ModuleProgress `json:"modules"`
+}
+
+type ModuleProgress struct {
+	ID            string              `json:"id"`
+	Title         string              `json:"title"`
+	Description   string              `json:"description,omitempty"`
+	Prerequisites []string            `json:"prerequisites,omitempty"`
+	Unlocked      bool                `json:"unlocked"`
+	Completed     bool                `json:"completed"`
+	Solved        int                 `json:"solved"`
+	Required      int                 `json:"required"`
+	Challenges    []ChallengeProgress `json:"challenges"`
+}
+
+type ChallengeProgress struct {
+	ID         string `json:"id"`
+	Title      string `json:"title"`
+	Difficulty string `json:"difficulty"`
+	Solved     bool   `json:"solved"`
+}
@@ -0,0 +1,140 @@
+package models
+
+// PublicChallenge is the view of a challenge served to students. Hidden test
+// cases keep only their ID and reference solutions are omitted. Hints holds
+// only the hints the student has unlocked; HintCount is how many there are.
+type PublicChallenge struct {
+	ID          string           `json:"id"`
+	Title       string           `json:"title"`
+	Difficulty  string           `json:"difficulty"`
+	Category    string           `json:"category,omitempty"`
+	Tags        []string         `json:"tags,omitempty"`
+	Languages   []string         `json:"languages"`
+	Description string           `json:"description"`
+	Hints       []string         `json:"hints"`
+	HintCount   int              `json:"hintCount"`
+	TestCases   []PublicTestCase `json:"testCases"`
+	InitialCode string           `json:"initialCode"`
+	TimeLimit   int              `json:"timeLimit"`
+	MemoryLimit int              `json:"memoryLimit"`
+	Memcheck    bool             `json:"memcheck,omitempty"`
+	Rules       *CodeRules       `json:"rules,omitempty"`
+	Subtasks    []Subtask        `json:"subtasks,omitempty"`
+	Locale      string           `json:"locale"`
+	Locales     []string         `json:"locales"`
+}
+
+type PublicTestCase struct {
+	ID             string `json:"id"`
+	Input          string `json:"input,omitempty"`
+	ExpectedOutput string `json:"expectedOutput,omitempty"`
+	Hidden         bool   `json:"hidden"`
+}
+
+// Public returns the student view in the default locale.
+func (c Challenge) Public() PublicChallenge {
+	return c.PublicIn(nil)
+}
+
+// PublicIn returns the student view in the best available match for the
+// preferred locales.
+func (c Challenge) PublicIn(preferred []string) PublicChallenge {
+	c, locale := c.Localize(preferred)
+
+	testCases := make([]PublicTestCase, 0, len(c.TestCases))
+	for _, tc := range c.TestCases {
+		public := PublicTestCase{ID: tc.ID, Hidden: tc.Hidden}
+		if !tc.Hidden {
+			public.Input = tc.Input
+			public.ExpectedOutput = tc.ExpectedOutput
+		}
+		testCases = append(testCases, public)
+	}
+
+	return PublicChallenge{
+		ID:          c.ID,
+		Title:       c.Title,
+		Difficulty:  c.Difficulty,
+		Category:    c.Category,
+		Tags:        c.Tags,
+		Languages:   c.SupportedLanguages(),
+		Description: c.Description,
+		Hints:       []string{},
+		HintCount:   len(c.Hints),
+		TestCases:   testCases,
+		InitialCode: c.InitialCode,
+		TimeLimit:   c.TimeLimit,
+		MemoryLimit: c.MemoryLimit,
+		Memcheck:    c.Memcheck,
+		Rules:       c.Rules,
+		Subtasks:    c.Subtasks,
+		Locale:      locale,
+		Locales:     c.Locales(),
+	}
+}
+
+// RedactResults strips output and error details from the results of hidden
+// test cases, leaving only the verdict, time and memory.
+func (c Challenge) RedactResults(results []TestResult) []TestResult {
+	hidden := make(map[string]bool)
+	for _, tc := range c.TestCases {
+		if tc.Hidden {
+			hidden[tc.ID] = true
+		}
+	}
+
+	redacted := make([]TestResult, 0, len(results))
+	for _, r := range results {
+		if hidden[r.TestCaseID] {
+			r = TestResult{
+				TestCaseID:    r.TestCaseID,
+				Passed:        r.Passed,
+				Verdict:       r.Verdict,
+				ExecutionTime: r.ExecutionTime,
+				Memory:        r.Memory,
+			}
+		}
+		redacted = append(redacted, r)
+	}
+	return redacted
+}
+
+// Reveal reasons record why a student was shown a challenge's solutions.
+const (
+	RevealSolved = "solved"
+	RevealGaveUp = "gave-up"
+)
+
+// SolutionView is the reference solutions and editorial of a challenge,
+// served once a student has solved it or given up.
+type SolutionView struct {
+	ChallengeID string   `json:"challengeId"`
+	Solutions   []string `json:"solutions"`
+	Editorial   string   `json:"editorial,omitempty"`
+	Reason      string   `json:"reason,omitempty"`
+	Locale      string   `json:"locale"`
+}
+
+// ChallengeSummary is the compact listing entry for a challenge, without
+// its statement, code or tests.
+type ChallengeSummary struct {
+	ID         string   `json:"id"`
+	Title      string   `json:"title"`
+	Difficulty string   `json:"difficulty"`
+	Category   string   `json:"category,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	Languages  []string `json:"languages"`
+	Solved     bool     `json:"solved"`
+}
+
+func (c Challenge) Summary(solved bool) ChallengeSummary {
+	return ChallengeSummary{
+		ID:         c.ID,
+		Title:      c.Title,
+		Difficulty: c.Difficulty,
+		Category:   c.Category,
+		Tags:       c.Tags,
+		Languages:  c.SupportedLanguages(),
+		Solved:     solved,
+	}
+}
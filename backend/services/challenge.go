@@ -1,96 +1,143 @@
-package services
-
-import (
-	"encoding/json"
-	"log"
-	"os"
-	"path/filepath"
-	"strings"
-
-	"strathlearn/backend/models"
-)
-
-func LoadChallenges(dir string) map[string]models.Challenge {
-	challenges := make(map[string]models.Challenge)
-	log.Printf("Loading challenges from directory: %s", dir)
-
-	files, err := os.ReadDir(dir)
-	if err != nil {
-		log.Printf("Error reading challenges directory: %v", err)
-		return challenges
-	}
-
-	for _, file := range files {
-		if filepath.Ext(file.Name()) == ".json" {
-			filePath := filepath.Join(dir, file.Name())
-			log.Printf("Processing file: %s", filePath)
-
-			data, err := os.ReadFile(filePath)
-			if err != nil {
-				log.Printf("Error reading file %s: %v", filePath, err)
-				continue
-			}
-
-			var challenge models.Challenge
-			if err := json.Unmarshal(data, &challenge); err != nil {
-				log.Printf("JSON parse error in %s: %v", filePath, err)
-				continue
-			}
-
-			challenge.FilePath = filePath
-
-			if challenge.ID == "" {
-				challenge.ID = strings.TrimSuffix(file.Name(), filepath.Ext(file.Name()))
-			}
-
-			log.Printf("Loaded challenge from %s: ID=%s, Title=%s",
-				filePath, challenge.ID, challenge.Title)
-
-			challenges[challenge.ID] = challenge
-		}
-	}
-
-	if len(challenges) == 0 {
-		log.Println("No challenges found, creating sample challenge")
-		CreateSampleChallenge(dir)
-		return LoadChallenges(dir)
-	}
-
-	return challenges
-}
-
-func CreateSampleChallenge(dir string) {
-	challengeJSON := `{
-        "id": "hello-world",
-        "title": "Hello, World",
-        "difficulty": "beginner",
-        "description": "Welcome to your first C programming challenge! Write a simple C program that prints the message 'Hello, World!' to the console.\n\nThis is the traditional first program for beginners in any programming language, and it will help you verify that your development environment is set up correctly.",
-        "hints": [
-            "Use the printf function from the stdio.h library to output text",
-            "Don't forget to include the stdio.h header at the top of your program",
-            "Remember that your main function should return an integer (typically 0 for successful execution)",
-            "In C, strings need to be enclosed in double quotes"
-        ],
-        "testCases": [
-            {
-                "id": "test1",
-                "input": "",
-                "expectedOutput": "Hello, World!",
-                "hidden": false
-            }
-        ],
-        "initialCode": "#include <stdio.h>\\n\\nint main() {\\n    // Write your code here\\n    \\n    return 0;\\n}",
-        "solutions": [
-            "#include <stdio.h>\\n\\nint main() {\\n    printf(\\\"Hello, World!\\\");\\n    return 0;\\n}"
-        ],
-        "timeLimit": 1,
-        "memoryLimit": 128
-    }`
-
-	filePath := filepath.Join(dir, "hello-world.json")
-	err := os.WriteFile(filePath, []byte(challengeJSON), 0644)
-	if err != nil {
-		log.Fatalf("Failed to write sample challenge: %v", err)
-	}
-	log.Printf("Created sample challenge at: %s", filePath)
-}
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"strathlearn/backend/models"
+)
+
+// LoadChallenges reads every challenge file under the given roots,
+// recursing into subdirectories.
+func LoadChallenges(roots ...string) map[string]models.Challenge {
+	log.Printf("Loading challenges from: %s", strings.Join(roots, ", "))
+	challenges, _ := loadChallengeRoots(roots)
+
+	if len(challenges) == 0 && len(roots) > 0 {
+		log.Println("No challenges found, creating sample challenge")
+		CreateSampleChallenge(roots[0])
+		return LoadChallenges(roots...)
+	}
+
+	return challenges
+}
+
+// LoadChallengeFiles loads the challenges under roots without creating a
+// sample challenge, along with the files that failed to load.
+func LoadChallengeFiles(roots ...string) (map[string]models.Challenge, map[string]error) {
+	return loadChallengeRoots(roots)
+}
+
+// loadChallengeRoots loads every challenge under roots and returns the files
+// that could not be loaded alongside the challenges that could.
+func loadChallengeRoots(roots []string) (map[string]models.Challenge, map[string]error) {
+	challenges := make(map[string]models.Challenge)
+	failed := make(map[string]error)
+	owners := make(map[string][]string)
+
+	files, errs := discoverChallengeFiles(roots)
+	for _, err := range errs {
+		log.Printf("Error reading challenges directory: %v", err)
+	}
+
+	for _, file := range files {
+		log.Printf("Processing file: %s", file.Path)
+
+		challenge, err := loadChallengeFile(file)
+		if err != nil {
+			log.Printf("Error loading %s: %v", file.Path, err)
+			failed[file.Path] = err
+			continue
+		}
+
+		log.Printf("Loaded challenge from %s: ID=%s, Title=%s",
+			file.Path, challenge.ID, challenge.Title)
+
+		owners[challenge.ID] = append(owners[challenge.ID], file.Path)
+		challenges[challenge.ID] = challenge
+	}
+
+	// Which copy of a duplicated ID is meant cannot be guessed, so every
+	// copy fails to load, as LintChallenges reports.
+	for id, paths := range owners {
+		if len(paths) < 2 {
+			continue
+		}
+		log.Printf("Duplicate challenge ID %s in %s, not loading any of them",
+			id, strings.Join(paths, ", "))
+		delete(challenges, id)
+		for _, path := range paths {
+			failed[path] = fmt.Errorf("duplicate challenge ID %q, also used by %s",
+				id, strings.Join(otherPaths(paths, path), ", "))
+		}
+	}
+
+	return challenges, failed
+}
+
+func loadChallengeFile(file challengeFile) (models.Challenge, error) {
+	var challenge models.Challenge
+
+	data, err := readChallengeJSON(file.Path)
+	if err != nil {
+		return challenge, err
+	}
+	if err := json.Unmarshal(data, &challenge); err != nil {
+		return challenge, fmt.Errorf("JSON parse error: %w", err)
+	}
+
+	challenge.FilePath = file.Path
+	if challenge.ID == "" {
+		name := filepath.Base(file.Path)
+		challenge.ID = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	if challenge.Category == "" {
+		challenge.Category = file.Category
+	}
+
+	if err := mergeGeneratedTests(&challenge); err != nil {
+		log.Printf("Error loading generated tests for %s: %v", file.Path, err)
+	}
+	return challenge, nil
+}
+
+func CreateSampleChallenge(dir string) {
+	challengeJSON := `{
+        "schemaVersion": 1,
+        "id": "hello-world",
+        "title": "Hello, World",
+        "difficulty": "beginner",
+        "description": "Welcome to your first C programming challenge! Write a simple C program that prints the message 'Hello, World!' to the console.\n\nThis is the traditional first program for beginners in any programming language, and it will help you verify that your development environment is set up correctly.",
+        "hints": [
+            "Use the printf function from the stdio.h library to output text",
+            "Don't forget to include the stdio.h header at the top of your program",
+            "Remember that your main function should return an integer (typically 0 for successful execution)",
+            "In C, strings need to be enclosed in double quotes"
+        ],
+        "testCases": [
+            {
+                "id": "test1",
+                "input": "",
+                "expectedOutput": "Hello, World!",
+                "hidden": false
+            }
+        ],
+        "initialCode": "#include <stdio.h>\n\nint main() {\n    // Write your code here\n    \n    return 0;\n}",
+        "solutions": [
+            "#include <stdio.h>\n\nint main() {\n    printf(\"Hello, World!\");\n    return 0;\n}"
+        ],
+        "timeLimit": 1,
+        "memoryLimit": 128
+    }`
+
+	filePath := filepath.Join(dir, "hello-world.json")
+	err := os.WriteFile(filePath, []byte(challengeJSON), 0644)
+	if err != nil {
+		log.Fatalf("Failed to write sample challenge: %v", err)
+	}
+	log.Printf("Created sample challenge at: %s", filePath)
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeChallengeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadChallengeFilesRejectsDuplicateIDs(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	writeChallengeFiles(t, first, map[string]string{
+		"sum.json":   validChallengeJSON,
+		"other.json": strings.Replace(validChallengeJSON, `"sum"`, `"other"`, 1),
+	})
+	writeChallengeFiles(t, second, map[string]string{
+		"nested/sum.json": strings.Replace(validChallengeJSON, `"Sum"`, `"Another sum"`, 1),
+	})
+
+	challenges, failed := LoadChallengeFiles(first, second)
+	if _, ok := challenges["sum"]; ok {
+		t.Errorf("duplicated challenge sum was loaded from %s", challenges["sum"].FilePath)
+	}
+	if _, ok := challenges["other"]; !ok || len(challenges) != 1 {
+		t.Errorf("loaded %v, want only other", challenges)
+	}
+
+	for _, path := range []string{filepath.Join(first, "sum.json"), filepath.Join(second, "nested", "sum.json")} {
+		err, ok := failed[path]
+		if !ok {
+			t.Errorf("%s did not fail to load", path)
+			continue
+		}
+		if !strings.Contains(err.Error(), "duplicate challenge ID") {
+			t.Errorf("%s failed with %v, want a duplicate ID error", path, err)
+		}
+	}
+}
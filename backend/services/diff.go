@@ -0,0 +1,140 @@
+package services
+
+import (
+	"errors"
+	"strings"
+)
+
+// MaxDiffLines bounds the size of each side of a diff; the diff takes time
+// quadratic in the number of lines, though only linear space.
+const MaxDiffLines = 2000
+
+var ErrDiffTooLarge = errors.New("code is too long to diff")
+
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+// DiffLine is one line of a diff. OldLine and NewLine are 1-based line
+// numbers, zero on the side the line is missing from.
+type DiffLine struct {
+	Op      string `json:"op"`
+	Text    string `json:"text"`
+	OldLine int    `json:"oldLine,omitempty"`
+	NewLine int    `json:"newLine,omitempty"`
+}
+
+// DiffLines compares two texts line by line using their longest common
+// subsequence. Deletions are listed before insertions at each change.
+func DiffLines(before, after string) ([]DiffLine, error) {
+	a := splitLines(before)
+	b := splitLines(after)
+	if len(a) > MaxDiffLines || len(b) > MaxDiffLines {
+		return nil, ErrDiffTooLarge
+	}
+
+	var matches [][2]int
+	commonLines(a, b, 0, 0, &matches)
+	matches = append(matches, [2]int{len(a), len(b)})
+
+	diff := make([]DiffLine, 0, max(len(a), len(b)))
+	i, j := 0, 0
+	for _, m := range matches {
+		for ; i < m[0]; i++ {
+			diff = append(diff, DiffLine{Op: DiffDelete, Text: a[i], OldLine: i + 1})
+		}
+		for ; j < m[1]; j++ {
+			diff = append(diff, DiffLine{Op: DiffInsert, Text: b[j], NewLine: j + 1})
+		}
+		if i < len(a) && j < len(b) {
+			diff = append(diff, DiffLine{Op: DiffEqual, Text: a[i], OldLine: i + 1, NewLine: j + 1})
+			i++
+			j++
+		}
+	}
+	return diff, nil
+}
+
+// commonLines appends the index pairs of a longest common subsequence of a
+// and b, offset by aOff and bOff, in order. It splits a in half and finds
+// where the subsequence crosses the split (Hirschberg's algorithm), so it
+// never holds more than two rows of lengths.
+func commonLines(a, b []string, aOff, bOff int, matches *[][2]int) {
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		*matches = append(*matches, [2]int{aOff, bOff})
+		a, b = a[1:], b[1:]
+		aOff++
+		bOff++
+	}
+	if len(a) == 0 || len(b) == 0 {
+		return
+	}
+	if len(a) == 1 {
+		for j := range b {
+			if b[j] == a[0] {
+				*matches = append(*matches, [2]int{aOff, bOff + j})
+				return
+			}
+		}
+		return
+	}
+
+	mid := len(a) / 2
+	head := prefixLengths(a[:mid], b)
+	tail := suffixLengths(a[mid:], b)
+	split := 0
+	for k := range head {
+		if head[k]+tail[k] > head[split]+tail[split] {
+			split = k
+		}
+	}
+	commonLines(a[:mid], b[:split], aOff, bOff, matches)
+	commonLines(a[mid:], b[split:], aOff+mid, bOff+split, matches)
+}
+
+// prefixLengths returns, for each k, the length of the longest common
+// subsequence of a and b[:k].
+func prefixLengths(a, b []string) []int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for i := range a {
+		for j := 1; j <= len(b); j++ {
+			if a[i] == b[j-1] {
+				cur[j] = prev[j-1] + 1
+			} else {
+				cur[j] = max(prev[j], cur[j-1])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev
+}
+
+// suffixLengths returns, for each k, the length of the longest common
+// subsequence of a and b[k:].
+func suffixLengths(a, b []string) []int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				cur[j] = prev[j+1] + 1
+			} else {
+				cur[j] = max(prev[j], cur[j+1])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev
+}
+
+func splitLines(s string) []string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
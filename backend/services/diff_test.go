@@ -0,0 +1,169 @@
+package services
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name          string
+		before, after string
+		want          []DiffLine
+	}{
+		{
+			name: "both empty",
+			want: []DiffLine{},
+		},
+		{
+			name:   "identical",
+			before: "a\nb\n",
+			after:  "a\nb",
+			want: []DiffLine{
+				{Op: DiffEqual, Text: "a", OldLine: 1, NewLine: 1},
+				{Op: DiffEqual, Text: "b", OldLine: 2, NewLine: 2},
+			},
+		},
+		{
+			name:  "from nothing",
+			after: "a\nb",
+			want: []DiffLine{
+				{Op: DiffInsert, Text: "a", NewLine: 1},
+				{Op: DiffInsert, Text: "b", NewLine: 2},
+			},
+		},
+		{
+			name:   "to nothing",
+			before: "a",
+			want:   []DiffLine{{Op: DiffDelete, Text: "a", OldLine: 1}},
+		},
+		{
+			name:   "changed line lists the deletion first",
+			before: "int main() {\n  return 1;\n}",
+			after:  "int main() {\n  return 0;\n}",
+			want: []DiffLine{
+				{Op: DiffEqual, Text: "int main() {", OldLine: 1, NewLine: 1},
+				{Op: DiffDelete, Text: "  return 1;", OldLine: 2},
+				{Op: DiffInsert, Text: "  return 0;", NewLine: 2},
+				{Op: DiffEqual, Text: "}", OldLine: 3, NewLine: 3},
+			},
+		},
+		{
+			name:   "windows line endings",
+			before: "a\r\nb\r\n",
+			after:  "a\nc\n",
+			want: []DiffLine{
+				{Op: DiffEqual, Text: "a", OldLine: 1, NewLine: 1},
+				{Op: DiffDelete, Text: "b", OldLine: 2},
+				{Op: DiffInsert, Text: "c", NewLine: 2},
+			},
+		},
+		{
+			name:   "insertions and deletions apart",
+			before: "a\nb\nc\nd",
+			after:  "x\na\nc\nd\ny",
+			want: []DiffLine{
+				{Op: DiffInsert, Text: "x", NewLine: 1},
+				{Op: DiffEqual, Text: "a", OldLine: 1, NewLine: 2},
+				{Op: DiffDelete, Text: "b", OldLine: 2},
+				{Op: DiffEqual, Text: "c", OldLine: 3, NewLine: 3},
+				{Op: DiffEqual, Text: "d", OldLine: 4, NewLine: 4},
+				{Op: DiffInsert, Text: "y", NewLine: 5},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DiffLines(tt.before, tt.after)
+			if err != nil {
+				t.Fatalf("DiffLines: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffLines =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffLinesTooLarge(t *testing.T) {
+	long := strings.Repeat("x\n", MaxDiffLines+1)
+	if _, err := DiffLines(long, "x"); !errors.Is(err, ErrDiffTooLarge) {
+		t.Errorf("long before: error = %v, want ErrDiffTooLarge", err)
+	}
+	if _, err := DiffLines("x", long); !errors.Is(err, ErrDiffTooLarge) {
+		t.Errorf("long after: error = %v, want ErrDiffTooLarge", err)
+	}
+	if _, err := DiffLines(strings.Repeat("x\n", MaxDiffLines), "y"); err != nil {
+		t.Errorf("diff at the limit: %v", err)
+	}
+}
+
+// lcsLength is the textbook quadratic-space longest common subsequence.
+func lcsLength(a, b []string) int {
+	table := make([][]int, len(a)+1)
+	for i := range table {
+		table[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				table[i][j] = table[i+1][j+1] + 1
+			} else {
+				table[i][j] = max(table[i+1][j], table[i][j+1])
+			}
+		}
+	}
+	return table[0][0]
+}
+
+// TestDiffLinesRandom checks that random diffs rebuild both texts, number
+// their lines correctly and keep as many lines as possible.
+func TestDiffLinesRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	alphabet := []string{"a", "b", "c", "d", "{", "}"}
+	randomLines := func() []string {
+		lines := make([]string, rng.Intn(40))
+		for i := range lines {
+			lines[i] = alphabet[rng.Intn(len(alphabet))]
+		}
+		return lines
+	}
+
+	for n := 0; n < 500; n++ {
+		a, b := randomLines(), randomLines()
+		diff, err := DiffLines(strings.Join(a, "\n"), strings.Join(b, "\n"))
+		if err != nil {
+			t.Fatalf("DiffLines: %v", err)
+		}
+
+		var old, new []string
+		equal := 0
+		for _, line := range diff {
+			if line.Op != DiffInsert {
+				old = append(old, line.Text)
+				if line.OldLine != len(old) {
+					t.Fatalf("%v -> %v: old line %d numbered %d", a, b, len(old), line.OldLine)
+				}
+			}
+			if line.Op != DiffDelete {
+				new = append(new, line.Text)
+				if line.NewLine != len(new) {
+					t.Fatalf("%v -> %v: new line %d numbered %d", a, b, len(new), line.NewLine)
+				}
+			}
+			if line.Op == DiffEqual {
+				equal++
+			}
+		}
+		if strings.Join(old, "\n") != strings.Join(a, "\n") || strings.Join(new, "\n") != strings.Join(b, "\n") {
+			t.Fatalf("%v -> %v: diff rebuilds %v -> %v", a, b, old, new)
+		}
+		if want := lcsLength(a, b); equal != want {
+			t.Fatalf("%v -> %v: %d equal lines, want %d", a, b, equal, want)
+		}
+	}
+}
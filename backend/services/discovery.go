@@ -0,0 +1,228 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// challengeFile is a challenge definition found under one of the roots.
+type challengeFile struct {
+	Path     string
+	Category string
+}
+
+// discoverChallengeFiles walks every root recursively and returns the
+// challenge files it finds. The directory a file sits in, relative to its
+// root, becomes the challenge category.
+func discoverChallengeFiles(roots []string) ([]challengeFile, []error) {
+	return discoverFiles(roots, isChallengeFile)
+}
+
+func discoverFiles(roots []string, match func(path string) bool) ([]challengeFile, []error) {
+	var files []challengeFile
+	var errs []error
+
+	for _, root := range roots {
+		if _, err := os.Stat(root); os.IsNotExist(err) {
+			continue
+		}
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path != root && strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !match(path) {
+				return nil
+			}
+
+			rel, err := filepath.Rel(root, filepath.Dir(path))
+			if err != nil || rel == "." {
+				rel = ""
+			}
+			files = append(files, challengeFile{
+				Path:     path,
+				Category: filepath.ToSlash(rel),
+			})
+			return nil
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error walking %s: %w", root, err))
+		}
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, errs
+}
+
+func isChallengeFile(path string) bool {
+	name := filepath.Base(path)
+	if isGeneratedTestsFile(name) || isTrackFile(name) {
+		return false
+	}
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json", ".yaml", ".yml":
+		return true
+	case ".md":
+		return hasFrontMatter(path)
+	}
+	return false
+}
+
+// readChallengeJSON reads a challenge file in any supported format and
+// returns it as JSON, so every format shares one decoder and linter.
+func readChallengeJSON(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return yamlToJSON(data)
+	case ".md":
+		return markdownToJSON(path, data)
+	default:
+		return data, nil
+	}
+}
+
+func yamlToJSON(data []byte) ([]byte, error) {
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("YAML parse error: %w", err)
+	}
+	if doc == nil {
+		doc = map[string]interface{}{}
+	}
+	return json.Marshal(doc)
+}
+
+// markdownToJSON converts a Markdown challenge. The YAML front matter holds
+// the challenge fields, the body becomes the description, and test cases
+// are read from sibling files named <name>.<test>.in and <name>.<test>.out.
+// Front matter may list testCases to set flags such as hidden.
+func markdownToJSON(path string, data []byte) ([]byte, error) {
+	front, body, ok := splitFrontMatter(data)
+	if !ok {
+		return nil, fmt.Errorf("missing front matter")
+	}
+
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(front, &doc); err != nil {
+		return nil, fmt.Errorf("front matter parse error: %w", err)
+	}
+	if doc == nil {
+		doc = map[string]interface{}{}
+	}
+	if description := strings.TrimSpace(string(body)); description != "" {
+		doc["description"] = description
+	}
+
+	tests, err := siblingTests(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(tests) > 0 {
+		declared, _ := doc["testCases"].([]interface{})
+		byID := make(map[string]map[string]interface{})
+		for _, item := range declared {
+			if tc, ok := item.(map[string]interface{}); ok {
+				byID[fmt.Sprint(tc["id"])] = tc
+			}
+		}
+		for _, test := range tests {
+			tc, ok := byID[test.id]
+			if !ok {
+				tc = map[string]interface{}{"id": test.id}
+				declared = append(declared, tc)
+			}
+			tc["id"] = test.id
+			tc["input"] = test.input
+			tc["expectedOutput"] = test.output
+		}
+		doc["testCases"] = declared
+	}
+
+	return json.Marshal(doc)
+}
+
+type fileTest struct {
+	id     string
+	input  string
+	output string
+}
+
+func siblingTests(path string) ([]fileTest, error) {
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	inputs, err := filepath.Glob(globEscape(base) + ".*.in")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(inputs)
+
+	tests := make([]fileTest, 0, len(inputs))
+	for _, in := range inputs {
+		id := strings.TrimSuffix(strings.TrimPrefix(in, base+"."), ".in")
+		out := strings.TrimSuffix(in, ".in") + ".out"
+
+		input, err := os.ReadFile(in)
+		if err != nil {
+			return nil, err
+		}
+		output, err := os.ReadFile(out)
+		if err != nil {
+			return nil, fmt.Errorf("test %s has no expected output: %w", id, err)
+		}
+		tests = append(tests, fileTest{id: id, input: string(input), output: string(output)})
+	}
+	return tests, nil
+}
+
+func splitFrontMatter(data []byte) ([]byte, []byte, bool) {
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	if !bytes.HasPrefix(data, []byte("---\n")) {
+		return nil, nil, false
+	}
+	rest := data[len("---\n"):]
+	end := bytes.Index(rest, []byte("\n---"))
+	if end < 0 {
+		return nil, nil, false
+	}
+	body := rest[end+len("\n---"):]
+	if i := bytes.IndexByte(body, '\n'); i >= 0 {
+		body = body[i+1:]
+	} else {
+		body = nil
+	}
+	return rest[:end], body, true
+}
+
+func hasFrontMatter(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	head := make([]byte, 4)
+	n, _ := f.Read(head)
+	return bytes.Equal(head[:n], []byte("---\n")) || bytes.HasPrefix(head[:n], []byte("---\r"))
+}
+
+func globEscape(s string) string {
+	replacer := strings.NewReplacer("*", `\*`, "?", `\?`, "[", `\[`)
+	return replacer.Replace(s)
+}
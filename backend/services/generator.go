@@ -0,0 +1,168 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"strathlearn/backend/models"
+	"strathlearn/backend/services/runner"
+)
+
+// Generated test cases are stored next to the challenge file with this
+// suffix, e.g. sum.json -> sum.generated.json.
+const generatedSuffix = ".generated.json"
+
+func GeneratedTestsPath(challengePath string) string {
+	return strings.TrimSuffix(challengePath, filepath.Ext(challengePath)) + generatedSuffix
+}
+
+func isGeneratedTestsFile(name string) bool {
+	return strings.HasSuffix(name, generatedSuffix)
+}
+
+// GenerateTests runs the challenge generator once per seed and the first
+// reference solution on every generated input.
+func GenerateTests(challenge models.Challenge, r runner.CodeRunner) ([]models.TestCase, error) {
+	gen := challenge.Generator
+	if gen == nil {
+		return nil, fmt.Errorf("challenge %s has no generator", challenge.ID)
+	}
+	if len(challenge.Solutions) == 0 {
+		return nil, fmt.Errorf("challenge %s has no reference solution", challenge.ID)
+	}
+	if len(gen.Seeds) == 0 {
+		return nil, fmt.Errorf("challenge %s generator has no seeds", challenge.ID)
+	}
+
+	source := gen.Source
+	if gen.File != "" {
+		path := gen.File
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(challenge.FilePath), path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading generator %s: %w", path, err)
+		}
+		source = string(data)
+	}
+	if source == "" {
+		return nil, fmt.Errorf("challenge %s generator has no source", challenge.ID)
+	}
+
+	genChallenge := executionOnly(challenge)
+	for i, seed := range gen.Seeds {
+		genChallenge.TestCases = append(genChallenge.TestCases, models.TestCase{
+			ID:    fmt.Sprintf("gen-%d", i+1),
+			Input: seed,
+		})
+	}
+
+	inputs, err := collectOutputs(r.RunTests(source, genChallenge), genChallenge.TestCases)
+	if err != nil {
+		return nil, fmt.Errorf("generator failed: %w", err)
+	}
+
+	refChallenge := executionOnly(challenge)
+	for i, input := range inputs {
+		refChallenge.TestCases = append(refChallenge.TestCases, models.TestCase{
+			ID:    genChallenge.TestCases[i].ID,
+			Input: input,
+		})
+	}
+
+	outputs, err := collectOutputs(r.RunTests(challenge.Solutions[0], refChallenge), refChallenge.TestCases)
+	if err != nil {
+		return nil, fmt.Errorf("reference solution failed: %w", err)
+	}
+
+	testCases := make([]models.TestCase, 0, len(inputs))
+	for i := range inputs {
+		testCases = append(testCases, models.TestCase{
+			ID:             refChallenge.TestCases[i].ID,
+			Input:          inputs[i],
+			ExpectedOutput: outputs[i],
+			Hidden:         gen.Hidden,
+		})
+	}
+	return testCases, nil
+}
+
+// WriteGeneratedTests stores generated test cases where LoadChallenges will
+// pick them up.
+func WriteGeneratedTests(challenge models.Challenge, testCases []models.TestCase) (string, error) {
+	data, err := json.MarshalIndent(testCases, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := GeneratedTestsPath(challenge.FilePath)
+	return path, os.WriteFile(path, data, 0644)
+}
+
+// mergeGeneratedTests appends previously generated test cases to a loaded
+// challenge. Test IDs already present in the challenge file win.
+func mergeGeneratedTests(challenge *models.Challenge) error {
+	data, err := os.ReadFile(GeneratedTestsPath(challenge.FilePath))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var generated []models.TestCase
+	if err := json.Unmarshal(data, &generated); err != nil {
+		return err
+	}
+
+	existing := make(map[string]bool, len(challenge.TestCases))
+	for _, tc := range challenge.TestCases {
+		existing[tc.ID] = true
+	}
+	for _, tc := range generated {
+		if !existing[tc.ID] {
+			challenge.TestCases = append(challenge.TestCases, tc)
+		}
+	}
+	return nil
+}
+
+// executionOnly copies the challenge limits without tests or grading rules,
+// for running author programs.
+func executionOnly(challenge models.Challenge) models.Challenge {
+	return models.Challenge{
+		ID:          challenge.ID,
+		TimeLimit:   challenge.TimeLimit,
+		MemoryLimit: challenge.MemoryLimit,
+		FilePath:    challenge.FilePath,
+	}
+}
+
+// collectOutputs returns the program output for every test, failing if the
+// program did not run cleanly. Tests have no expected output, so a wrong
+// answer verdict just means the program printed something.
+func collectOutputs(results []models.TestResult, tests []models.TestCase) ([]string, error) {
+	byID := make(map[string]models.TestResult, len(results))
+	for _, result := range results {
+		byID[result.TestCaseID] = result
+	}
+
+	outputs := make([]string, 0, len(tests))
+	for _, tc := range tests {
+		result, ok := byID[tc.ID]
+		if !ok {
+			if len(results) == 1 {
+				return nil, fmt.Errorf("%s", results[0].Error)
+			}
+			return nil, fmt.Errorf("no result for %s", tc.ID)
+		}
+		if result.Verdict != models.VerdictAccepted && result.Verdict != models.VerdictWrongAnswer {
+			return nil, fmt.Errorf("%s: %s", tc.ID, result.Error)
+		}
+		outputs = append(outputs, result.Output)
+	}
+	return outputs, nil
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"strathlearn/backend/db"
+	"strathlearn/backend/models"
+)
+
+var (
+	ErrNoMoreHints    = errors.New("all hints are already unlocked")
+	ErrHintNotYetOpen = errors.New("next hint is not available yet")
+)
+
+type UnlockedHint struct {
+	Index      int       `json:"index"`
+	Text       string    `json:"text"`
+	UnlockedAt time.Time `json:"unlockedAt"`
+}
+
+// HintStatus is what a user has unlocked of a challenge's hints and when the
+// next one becomes available.
+type HintStatus struct {
+	ChallengeID     string         `json:"challengeId"`
+	Total           int            `json:"total"`
+	Unlocked        []UnlockedHint `json:"unlocked"`
+	NextAvailableAt *time.Time     `json:"nextAvailableAt,omitempty"`
+	AttemptsNeeded  int            `json:"attemptsNeeded,omitempty"`
+	Penalty         int            `json:"penalty,omitempty"`
+}
+
+// CanUnlock reports whether the next hint can be unlocked at now.
+func (s HintStatus) CanUnlock(now time.Time) bool {
+	if len(s.Unlocked) >= s.Total || s.AttemptsNeeded > 0 {
+		return false
+	}
+	return s.NextAvailableAt == nil || !now.Before(*s.NextAvailableAt)
+}
+
+// HintRepository stores which hints users have unlocked.
+type HintRepository interface {
+	// Unlocks returns userID's unlocks of a challenge's hints in hint order.
+	Unlocks(userID, challengeID string) ([]db.HintUnlock, error)
+	// Unlock records an unlock. Unlocking the same hint twice is a no-op.
+	Unlock(unlock db.HintUnlock) error
+	// Usage counts the hints userID has unlocked on each challenge.
+	Usage(userID string) (map[string]int, error)
+}
+
+// HintStatusFor loads the hints userID has unlocked. The challenge should
+// already be localized so the hint texts are in the user's language.
+func HintStatusFor(hints HintRepository, submissions SubmissionRepository, userID string, challenge models.Challenge) (HintStatus, error) {
+	status := HintStatus{
+		ChallengeID: challenge.ID,
+		Total:       len(challenge.Hints),
+		Unlocked:    []UnlockedHint{},
+		Penalty:     hintPenalty(challenge),
+	}
+
+	unlocks, err := hints.Unlocks(userID, challenge.ID)
+	if err != nil {
+		return status, err
+	}
+	var last time.Time
+	for _, unlock := range unlocks {
+		if unlock.HintIndex >= len(challenge.Hints) {
+			continue
+		}
+		status.Unlocked = append(status.Unlocked, UnlockedHint{
+			Index:      unlock.HintIndex,
+			Text:       challenge.Hints[unlock.HintIndex],
+			UnlockedAt: unlock.UnlockedAt,
+		})
+		if unlock.UnlockedAt.After(last) {
+			last = unlock.UnlockedAt
+		}
+	}
+
+	policy := challenge.HintPolicy
+	if policy == nil || len(status.Unlocked) >= status.Total {
+		return status, nil
+	}
+	if policy.CooldownSeconds > 0 && !last.IsZero() {
+		next := last.Add(time.Duration(policy.CooldownSeconds) * time.Second)
+		status.NextAvailableAt = &next
+	}
+	if policy.AttemptsPerHint > 0 {
+		failed, err := submissions.FailedAttempts(userID, challenge.ID)
+		if err != nil {
+			return status, err
+		}
+		required := (len(status.Unlocked) + 1) * policy.AttemptsPerHint
+		status.AttemptsNeeded = max(required-failed, 0)
+	}
+	return status, nil
+}
+
+// UnlockNextHint reveals the lowest hint userID has not unlocked yet.
+func UnlockNextHint(hints HintRepository, submissions SubmissionRepository, userID string, challenge models.Challenge, now time.Time) (HintStatus, error) {
+	status, err := HintStatusFor(hints, submissions, userID, challenge)
+	if err != nil {
+		return status, err
+	}
+	if len(status.Unlocked) >= status.Total {
+		return status, ErrNoMoreHints
+	}
+	if !status.CanUnlock(now) {
+		return status, ErrHintNotYetOpen
+	}
+
+	index := 0
+	for _, hint := range status.Unlocked {
+		if hint.Index == index {
+			index++
+		}
+	}
+	// A concurrent request may have unlocked the same hint first; either
+	// way it is unlocked now.
+	err = hints.Unlock(db.HintUnlock{
+		UserID:      userID,
+		ChallengeID: challenge.ID,
+		HintIndex:   index,
+		UnlockedAt:  now,
+	})
+	if err != nil {
+		return status, err
+	}
+	return HintStatusFor(hints, submissions, userID, challenge)
+}
+
+// HintsUsed counts the hints userID has unlocked for a challenge. Unlocks of
+// hints the challenge no longer has, because an author removed them, do not
+// count.
+func HintsUsed(hints HintRepository, userID string, challenge models.Challenge) (int, error) {
+	unlocks, err := hints.Unlocks(userID, challenge.ID)
+	if err != nil {
+		return 0, err
+	}
+	used := 0
+	for _, unlock := range unlocks {
+		if unlock.HintIndex < len(challenge.Hints) {
+			used++
+		}
+	}
+	return used, nil
+}
+
+func hintPenalty(challenge models.Challenge) int {
+	if challenge.HintPolicy == nil {
+		return 0
+	}
+	return challenge.HintPolicy.Penalty
+}
+
+// DBHintStore keeps hint unlocks in the database.
+type DBHintStore struct {
+	db *gorm.DB
+}
+
+func NewDBHintStore(conn *gorm.DB) *DBHintStore {
+	return &DBHintStore{db: conn}
+}
+
+func (s *DBHintStore) Unlocks(userID, challengeID string) ([]db.HintUnlock, error) {
+	var unlocks []db.HintUnlock
+	err := s.db.Where("user_id = ? AND challenge_id = ?", userID, challengeID).
+		Order("hint_index ASC").Find(&unlocks).Error
+	return unlocks, err
+}
+
+func (s *DBHintStore) Unlock(unlock db.HintUnlock) error {
+	return s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&unlock).Error
+}
+
+func (s *DBHintStore) Usage(userID string) (map[string]int, error) {
+	var counts []struct {
+		ChallengeID string
+		Count       int
+	}
+	err := s.db.Model(&db.HintUnlock{}).
+		Select("challenge_id, COUNT(*) as count").
+		Where("user_id = ?", userID).
+		Group("challenge_id").
+		Find(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	usage := make(map[string]int, len(counts))
+	for _, c := range counts {
+		usage[c.ChallengeID] = c.Count
+	}
+	return usage, nil
+}
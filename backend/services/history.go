@@ -0,0 +1,129 @@
+package services
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"strathlearn/backend/db"
+)
+
+// SubmissionQuery filters a user's submission history. Empty fields do not
+// filter; To is exclusive.
+type SubmissionQuery struct {
+	UserID      string
+	ChallengeID string
+	Verdict     string
+	From        time.Time
+	To          time.Time
+	Cursor      string
+	Limit       int
+}
+
+// SubmissionSummary is a history entry, without code or test results.
+type SubmissionSummary struct {
+	ID               string    `json:"id"`
+	ChallengeID      string    `json:"challengeId"`
+	ChallengeVersion int       `json:"challengeVersion,omitempty"`
+	Language         string    `json:"language"`
+	Verdict          string    `json:"verdict"`
+	Score            int       `json:"score"`
+	MaxScore         int       `json:"maxScore"`
+	TestsPassed      int       `json:"testsPassed"`
+	TestsTotal       int       `json:"testsTotal"`
+	HintsUsed        int       `json:"hintsUsed,omitempty"`
+	CreatedAt        time.Time `json:"createdAt"`
+}
+
+type SubmissionPage struct {
+	Submissions []SubmissionSummary `json:"submissions"`
+	NextCursor  string              `json:"nextCursor,omitempty"`
+}
+
+// List returns one page of a user's history, newest first. The cursor
+// encodes the time and ID of the last submission returned.
+func (s *DBSubmissionStore) List(q SubmissionQuery) (SubmissionPage, error) {
+	limit := pageLimit(q.Limit)
+
+	query := s.db.Model(&db.Submission{}).Where("user_id = ?", q.UserID)
+	if q.ChallengeID != "" {
+		query = query.Where("challenge_id = ?", q.ChallengeID)
+	}
+	if q.Verdict != "" {
+		query = query.Where("verdict = ?", q.Verdict)
+	}
+	if !q.From.IsZero() {
+		query = query.Where("created_at >= ?", q.From)
+	}
+	if !q.To.IsZero() {
+		query = query.Where("created_at < ?", q.To)
+	}
+	if q.Cursor != "" {
+		createdAt, id, err := decodeSubmissionCursor(q.Cursor)
+		if err != nil {
+			return SubmissionPage{}, err
+		}
+		query = query.Where("created_at < ? OR (created_at = ? AND id < ?)", createdAt, createdAt, id)
+	}
+
+	var rows []db.Submission
+	err := query.Omit("code", "stdout", "stderr", "compile_output").
+		Order("created_at DESC, id DESC").Limit(limit + 1).Find(&rows).Error
+	if err != nil {
+		return SubmissionPage{}, err
+	}
+	return submissionPage(rows, limit), nil
+}
+
+// submissionPage turns up to limit+1 rows, newest first, into a page.
+func submissionPage(rows []db.Submission, limit int) SubmissionPage {
+	page := SubmissionPage{Submissions: make([]SubmissionSummary, 0, min(len(rows), limit))}
+	for i, row := range rows {
+		if i == limit {
+			last := rows[limit-1]
+			page.NextCursor = encodeSubmissionCursor(last.CreatedAt, last.ID)
+			break
+		}
+		page.Submissions = append(page.Submissions, SubmissionSummary{
+			ID:               row.ID,
+			ChallengeID:      row.ChallengeID,
+			ChallengeVersion: row.ChallengeVersion,
+			Language:         row.Language,
+			Verdict:          row.Verdict,
+			Score:            row.Score,
+			MaxScore:         row.MaxScore,
+			TestsPassed:      row.TestsPassed,
+			TestsTotal:       row.TestsTotal,
+			HintsUsed:        row.HintsUsed,
+			CreatedAt:        row.CreatedAt,
+		})
+	}
+	return page
+}
+
+func pageLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultPageSize
+	}
+	return min(limit, MaxPageSize)
+}
+
+func encodeSubmissionCursor(createdAt time.Time, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(createdAt.UTC().Format(time.RFC3339Nano) + "|" + id))
+}
+
+func decodeSubmissionCursor(cursor string) (time.Time, string, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+	stamp, id, ok := strings.Cut(string(decoded), "|")
+	if !ok {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, stamp)
+	if err != nil {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+	return createdAt, id, nil
+}
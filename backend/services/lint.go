@@ -0,0 +1,524 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"strathlearn/backend/models"
+	"strathlearn/backend/services/rules"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+type LintIssue struct {
+	File        string `json:"file"`
+	ChallengeID string `json:"challengeId,omitempty"`
+	Severity    string `json:"severity"`
+	Field       string `json:"field,omitempty"`
+	Message     string `json:"message"`
+}
+
+func (i LintIssue) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %s:", i.File, i.Severity)
+	if i.ChallengeID != "" {
+		fmt.Fprintf(&b, " [%s]", i.ChallengeID)
+	}
+	if i.Field != "" {
+		fmt.Fprintf(&b, " %s:", i.Field)
+	}
+	b.WriteString(" " + i.Message)
+	return b.String()
+}
+
+type LintReport struct {
+	Files  int         `json:"files"`
+	Issues []LintIssue `json:"issues"`
+}
+
+func (r LintReport) count(severity string) int {
+	n := 0
+	for _, issue := range r.Issues {
+		if issue.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+func (r LintReport) Errors() int   { return r.count(SeverityError) }
+func (r LintReport) Warnings() int { return r.count(SeverityWarning) }
+
+// LintChallenges strictly validates every challenge file under the roots,
+// including checks that span files such as duplicate challenge IDs.
+func LintChallenges(roots ...string) LintReport {
+	var report LintReport
+
+	files, errs := discoverChallengeFiles(roots)
+	for _, err := range errs {
+		report.Issues = append(report.Issues, LintIssue{
+			File: strings.Join(roots, ", "), Severity: SeverityError, Message: err.Error(),
+		})
+	}
+
+	owners := make(map[string][]string)
+	var decoded []models.Challenge
+	for _, file := range files {
+		report.Files++
+
+		data, err := readChallengeJSON(file.Path)
+		if err != nil {
+			report.Issues = append(report.Issues, LintIssue{
+				File: file.Path, Severity: SeverityError, Message: err.Error(),
+			})
+			continue
+		}
+
+		challenge, issues := LintChallengeFile(file.Path, data)
+		report.Issues = append(report.Issues, issues...)
+		if challenge.ID != "" {
+			owners[challenge.ID] = append(owners[challenge.ID], file.Path)
+		}
+		decoded = append(decoded, challenge)
+	}
+	report.Issues = append(report.Issues, lintLocaleCoverage(decoded)...)
+
+	ids := make([]string, 0, len(owners))
+	for id := range owners {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if paths := owners[id]; len(paths) > 1 {
+			for _, path := range paths {
+				report.Issues = append(report.Issues, LintIssue{
+					File:        path,
+					ChallengeID: id,
+					Severity:    SeverityError,
+					Field:       "id",
+					Message:     "duplicate challenge ID, also used by " + strings.Join(otherPaths(paths, path), ", "),
+				})
+			}
+		}
+	}
+
+	tracks, failed := LoadTracks(roots...)
+	paths := make([]string, 0, len(failed))
+	for path := range failed {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		report.Issues = append(report.Issues, LintIssue{
+			File: path, Severity: SeverityError, Message: failed[path].Error(),
+		})
+	}
+	known := make(map[string]models.Challenge, len(owners))
+	for id := range owners {
+		known[id] = models.Challenge{ID: id}
+	}
+	report.Issues = append(report.Issues, ValidateTracks(tracks, known)...)
+	report.Files += len(tracks) + len(failed)
+
+	return report
+}
+
+// lintLocaleCoverage warns about challenges that lack a translation most
+// other challenges have, so a half-translated set is easy to spot.
+func lintLocaleCoverage(challenges []models.Challenge) []LintIssue {
+	counts := make(map[string]int)
+	for _, challenge := range challenges {
+		for locale := range challenge.Translations {
+			if models.ValidLocale(locale) && !strings.EqualFold(locale, models.DefaultLocale) {
+				counts[locale]++
+			}
+		}
+	}
+	locales := make([]string, 0, len(counts))
+	for locale := range counts {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+
+	var issues []LintIssue
+	for _, challenge := range challenges {
+		for _, locale := range locales {
+			if _, ok := challenge.Translations[locale]; ok {
+				continue
+			}
+			issues = append(issues, LintIssue{
+				File:        challenge.FilePath,
+				ChallengeID: challenge.ID,
+				Severity:    SeverityWarning,
+				Field:       "translations",
+				Message:     fmt.Sprintf("missing %s translation, which %d other challenges have", locale, counts[locale]),
+			})
+		}
+	}
+	return issues
+}
+
+// LintChallengeFile validates a single challenge file. The returned challenge
+// is decoded leniently so that later checks can still run on it.
+func LintChallengeFile(path string, data []byte) (models.Challenge, []LintIssue) {
+	var challenge models.Challenge
+	var issues []LintIssue
+	add := func(severity, field, format string, args ...interface{}) {
+		issues = append(issues, LintIssue{
+			File:        path,
+			ChallengeID: challenge.ID,
+			Severity:    severity,
+			Field:       field,
+			Message:     fmt.Sprintf(format, args...),
+		})
+	}
+
+	if !json.Valid(data) {
+		var syntaxErr *json.SyntaxError
+		err := json.Unmarshal(data, &struct{}{})
+		if errors.As(err, &syntaxErr) {
+			line, col := position(data, syntaxErr.Offset)
+			add(SeverityError, "", "invalid JSON at line %d, column %d: %v", line, col, err)
+		} else {
+			add(SeverityError, "", "invalid JSON: %v", err)
+		}
+		return challenge, issues
+	}
+
+	for _, problem := range checkValue(data, reflect.TypeOf(challenge), "") {
+		add(SeverityError, problem.field, "%s", problem.message)
+	}
+
+	// Decode what we can; fields with type errors keep their zero value.
+	if err := json.Unmarshal(data, &challenge); err != nil {
+		var partial map[string]json.RawMessage
+		json.Unmarshal(data, &partial)
+		challenge = decodeLeniently(partial)
+	}
+	challenge.FilePath = path
+	if challenge.ID == "" {
+		challenge.ID = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		add(SeverityWarning, "id", "missing id, defaulting to file name")
+	}
+	for i := range issues {
+		issues[i].ChallengeID = challenge.ID
+	}
+
+	if challenge.SchemaVersion > models.ChallengeSchemaVersion {
+		add(SeverityError, "schemaVersion", "schema version %d is newer than supported version %d",
+			challenge.SchemaVersion, models.ChallengeSchemaVersion)
+	}
+	if strings.TrimSpace(challenge.Title) == "" {
+		add(SeverityError, "title", "missing title")
+	}
+	if strings.TrimSpace(challenge.Description) == "" {
+		add(SeverityError, "description", "missing description")
+	}
+	if challenge.TimeLimit <= 0 {
+		add(SeverityError, "timeLimit", "time limit must be a positive number of seconds")
+	}
+	if challenge.MemoryLimit <= 0 {
+		add(SeverityError, "memoryLimit", "memory limit must be a positive number of megabytes")
+	}
+	if len(challenge.TestCases) == 0 && challenge.Generator == nil {
+		add(SeverityError, "testCases", "challenge has no test cases")
+	}
+	if len(challenge.Solutions) == 0 {
+		add(SeverityWarning, "solutions", "challenge has no reference solutions")
+	}
+
+	testIDs := make(map[string]bool, len(challenge.TestCases))
+	for i, tc := range challenge.TestCases {
+		field := fmt.Sprintf("testCases[%d]", i)
+		switch {
+		case tc.ID == "":
+			add(SeverityError, field+".id", "missing test case ID")
+		case testIDs[tc.ID]:
+			add(SeverityError, field+".id", "duplicate test case ID %q", tc.ID)
+		}
+		testIDs[tc.ID] = true
+		if strings.TrimSpace(tc.ExpectedOutput) == "" {
+			add(SeverityError, field+".expectedOutput", "expected output is empty")
+		}
+	}
+
+	if hasLiteralEscapes(challenge.InitialCode) {
+		add(SeverityError, "initialCode", `code contains a literal "\n" outside a string; newlines are probably double-escaped`)
+	}
+	for i, solution := range challenge.Solutions {
+		if hasLiteralEscapes(solution) {
+			add(SeverityError, fmt.Sprintf("solutions[%d]", i), `code contains a literal "\n" outside a string; newlines are probably double-escaped`)
+		}
+	}
+
+	locales := make([]string, 0, len(challenge.Translations))
+	for locale := range challenge.Translations {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	for _, locale := range locales {
+		t := challenge.Translations[locale]
+		field := "translations." + locale
+		switch {
+		case !models.ValidLocale(locale):
+			add(SeverityError, field, "invalid locale %q, want a language tag such as sw or sw-KE", locale)
+			continue
+		case strings.EqualFold(locale, models.DefaultLocale):
+			add(SeverityError, field, "%s is the default locale; edit the untranslated fields instead", locale)
+			continue
+		}
+		if t.Title == "" {
+			add(SeverityWarning, field+".title", "missing %s translation of the title", locale)
+		}
+		if t.Description == "" {
+			add(SeverityWarning, field+".description", "missing %s translation of the description", locale)
+		}
+		switch {
+		case len(t.Hints) > len(challenge.Hints):
+			add(SeverityError, field+".hints", "%d hints translated to %s but the challenge has %d", len(t.Hints), locale, len(challenge.Hints))
+		case len(challenge.Hints) > 0 && len(t.Hints) < len(challenge.Hints):
+			add(SeverityWarning, field+".hints", "%d of %d hints translated to %s", len(t.Hints), len(challenge.Hints), locale)
+		}
+		if challenge.Editorial != "" && t.Editorial == "" {
+			add(SeverityWarning, field+".editorial", "missing %s translation of the editorial", locale)
+		}
+		if hasLiteralEscapes(t.InitialCode) {
+			add(SeverityError, field+".initialCode", `code contains a literal "\n" outside a string; newlines are probably double-escaped`)
+		}
+	}
+
+	if policy := challenge.HintPolicy; policy != nil {
+		if policy.AttemptsPerHint < 0 {
+			add(SeverityError, "hintPolicy.attemptsPerHint", "attempts per hint cannot be negative")
+		}
+		if policy.CooldownSeconds < 0 {
+			add(SeverityError, "hintPolicy.cooldownSeconds", "cooldown cannot be negative")
+		}
+		if policy.Penalty < 0 || policy.Penalty > 100 {
+			add(SeverityError, "hintPolicy.penalty", "penalty must be a percentage between 0 and 100")
+		}
+		if len(challenge.Hints) == 0 {
+			add(SeverityWarning, "hintPolicy", "hint policy set but the challenge has no hints")
+		}
+	}
+
+	if challenge.Rules != nil {
+		for _, construct := range challenge.Rules.RequiredConstructs {
+			if !rules.IsKnownConstruct(construct) {
+				add(SeverityError, "rules.requiredConstructs", "unknown construct %q", construct)
+			}
+		}
+	}
+
+	subtaskIDs := make(map[string]bool, len(challenge.Subtasks))
+	for _, st := range challenge.Subtasks {
+		subtaskIDs[st.ID] = true
+	}
+	for i, st := range challenge.Subtasks {
+		field := fmt.Sprintf("subtasks[%d]", i)
+		for _, id := range st.TestCases {
+			if !testIDs[id] && challenge.Generator == nil {
+				add(SeverityError, field+".testCases", "unknown test case %q", id)
+			}
+		}
+		for _, dep := range st.DependsOn {
+			if !subtaskIDs[dep] {
+				add(SeverityError, field+".dependsOn", "unknown subtask %q", dep)
+			}
+		}
+	}
+
+	if challenge.Generator != nil && len(challenge.Generator.Seeds) == 0 {
+		add(SeverityError, "generator.seeds", "generator has no seeds")
+	}
+
+	return challenge, issues
+}
+
+type fieldProblem struct {
+	field   string
+	message string
+}
+
+// checkValue compares raw JSON against the Go type it decodes into. It
+// reports unknown keys, keys that only match a field case-insensitively
+// (encoding/json would accept them silently) and values of the wrong type.
+func checkValue(raw json.RawMessage, t reflect.Type, path string) []fieldProblem {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			return []fieldProblem{{path, "expected an object, got " + jsonKind(raw)}}
+		}
+
+		fields := make(map[string]reflect.StructField)
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name := strings.Split(f.Tag.Get("json"), ",")[0]
+			if !f.IsExported() || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			fields[name] = f
+		}
+
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		var problems []fieldProblem
+		for _, key := range keys {
+			field := joinPath(path, key)
+			if f, ok := fields[key]; ok {
+				problems = append(problems, checkValue(obj[key], f.Type, field)...)
+				continue
+			}
+
+			suggestion := ""
+			for name := range fields {
+				if strings.EqualFold(name, key) {
+					suggestion = name
+				}
+			}
+			if suggestion != "" {
+				problems = append(problems, fieldProblem{field, fmt.Sprintf("unknown field, did you mean %q?", suggestion)})
+			} else {
+				problems = append(problems, fieldProblem{field, "unknown field"})
+			}
+		}
+		return problems
+	case reflect.Slice:
+		var items []json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return []fieldProblem{{path, "expected an array, got " + jsonKind(raw)}}
+		}
+		var problems []fieldProblem
+		for i, item := range items {
+			problems = append(problems, checkValue(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return problems
+	default:
+		if err := json.Unmarshal(raw, reflect.New(t).Interface()); err != nil {
+			return []fieldProblem{{path, fmt.Sprintf("expected %s, got %s", jsonTypeName(t), jsonKind(raw))}}
+		}
+		return nil
+	}
+}
+
+// decodeLeniently decodes each top-level field on its own so one bad value
+// does not hide the rest of the challenge.
+func decodeLeniently(obj map[string]json.RawMessage) models.Challenge {
+	var challenge models.Challenge
+	for key, value := range obj {
+		single, _ := json.Marshal(map[string]json.RawMessage{key: value})
+		json.Unmarshal(single, &challenge)
+	}
+	return challenge
+}
+
+// hasLiteralEscapes reports whether code contains a \n or \t escape outside
+// C string and character literals, which means the JSON file escaped the
+// backslash as well as the newline.
+func hasLiteralEscapes(code string) bool {
+	var quote byte
+	for i := 0; i < len(code); i++ {
+		c := code[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote || c == '\n' {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '\\' && i+1 < len(code) && (code[i+1] == 'n' || code[i+1] == 't'):
+			return true
+		}
+	}
+	return false
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	default:
+		return t.Kind().String()
+	}
+}
+
+func jsonKind(raw json.RawMessage) string {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 {
+		return "nothing"
+	}
+	switch trimmed[0] {
+	case '"':
+		return "a string"
+	case '{':
+		return "an object"
+	case '[':
+		return "an array"
+	case 't', 'f':
+		return "a boolean"
+	case 'n':
+		return "null"
+	default:
+		return "a number"
+	}
+}
+
+func position(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := int(offset) - bytes.LastIndexByte(before, '\n')
+	return line, col
+}
+
+func otherPaths(paths []string, self string) []string {
+	others := make([]string, 0, len(paths)-1)
+	for _, p := range paths {
+		if p != self {
+			others = append(others, p)
+		}
+	}
+	return others
+}
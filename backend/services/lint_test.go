@@ -0,0 +1,263 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const validChallengeJSON = `{
+  "id": "sum",
+  "title": "Sum",
+  "description": "Add two numbers.",
+  "difficulty": "easy",
+  "timeLimit": 1,
+  "memoryLimit": 64,
+  "initialCode": "int main() {\n  printf(\"\\n\");\n}",
+  "solutions": ["int main() { return 0; }"],
+  "testCases": [
+    {"id": "t1", "input": "1 2", "expectedOutput": "3"}
+  ]
+}`
+
+// issueFields returns "severity field" for each issue so tests can compare
+// them without depending on message wording.
+func issueFields(issues []LintIssue) []string {
+	out := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		out = append(out, issue.Severity+" "+issue.Field)
+	}
+	return out
+}
+
+func hasIssue(issues []LintIssue, severity, field string) bool {
+	for _, issue := range issues {
+		if issue.Severity == severity && issue.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+func TestLintChallengeFileValid(t *testing.T) {
+	challenge, issues := LintChallengeFile("sum.json", []byte(validChallengeJSON))
+	if len(issues) != 0 {
+		t.Fatalf("issues = %v, want none", issueFields(issues))
+	}
+	if challenge.ID != "sum" || challenge.FilePath != "sum.json" || len(challenge.TestCases) != 1 {
+		t.Errorf("decoded challenge = %+v", challenge)
+	}
+}
+
+func TestLintChallengeFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		severity string
+		field    string
+	}{
+		{
+			name:     "unknown field",
+			json:     `{"id": "x", "tittle": "X"}`,
+			severity: SeverityError,
+			field:    "tittle",
+		},
+		{
+			name:     "field in the wrong case",
+			json:     `{"id": "x", "TimeLimit": 1}`,
+			severity: SeverityError,
+			field:    "TimeLimit",
+		},
+		{
+			name:     "wrong type",
+			json:     `{"id": "x", "timeLimit": "one"}`,
+			severity: SeverityError,
+			field:    "timeLimit",
+		},
+		{
+			name:     "nested unknown field",
+			json:     `{"id": "x", "testCases": [{"id": "t1", "expected": "3"}]}`,
+			severity: SeverityError,
+			field:    "testCases[0].expected",
+		},
+		{
+			name:     "missing id",
+			json:     `{"title": "X"}`,
+			severity: SeverityWarning,
+			field:    "id",
+		},
+		{
+			name:     "missing title",
+			json:     `{"id": "x"}`,
+			severity: SeverityError,
+			field:    "title",
+		},
+		{
+			name:     "no test cases",
+			json:     `{"id": "x"}`,
+			severity: SeverityError,
+			field:    "testCases",
+		},
+		{
+			name:     "duplicate test case",
+			json:     `{"id": "x", "testCases": [{"id": "t1", "expectedOutput": "1"}, {"id": "t1", "expectedOutput": "2"}]}`,
+			severity: SeverityError,
+			field:    "testCases[1].id",
+		},
+		{
+			name:     "empty expected output",
+			json:     `{"id": "x", "testCases": [{"id": "t1", "expectedOutput": "  "}]}`,
+			severity: SeverityError,
+			field:    "testCases[0].expectedOutput",
+		},
+		{
+			name:     "double escaped newlines",
+			json:     `{"id": "x", "initialCode": "int main() {\\n}"}`,
+			severity: SeverityError,
+			field:    "initialCode",
+		},
+		{
+			name:     "unknown construct",
+			json:     `{"id": "x", "rules": {"requiredConstructs": ["goto"]}}`,
+			severity: SeverityError,
+			field:    "rules.requiredConstructs",
+		},
+		{
+			name:     "subtask with unknown test",
+			json:     `{"id": "x", "subtasks": [{"id": "s", "points": 1, "testCases": ["nope"]}]}`,
+			severity: SeverityError,
+			field:    "subtasks[0].testCases",
+		},
+		{
+			name:     "subtask with unknown dependency",
+			json:     `{"id": "x", "subtasks": [{"id": "s", "points": 1, "dependsOn": ["nope"]}]}`,
+			severity: SeverityError,
+			field:    "subtasks[0].dependsOn",
+		},
+		{
+			name:     "invalid locale",
+			json:     `{"id": "x", "translations": {"not a locale": {"title": "X"}}}`,
+			severity: SeverityError,
+			field:    "translations.not a locale",
+		},
+		{
+			name:     "more translated hints than hints",
+			json:     `{"id": "x", "hints": ["h"], "translations": {"sw": {"hints": ["a", "b"]}}}`,
+			severity: SeverityError,
+			field:    "translations.sw.hints",
+		},
+		{
+			name:     "fewer translated hints than hints",
+			json:     `{"id": "x", "hints": ["h", "i"], "translations": {"sw": {"hints": ["a"]}}}`,
+			severity: SeverityWarning,
+			field:    "translations.sw.hints",
+		},
+		{
+			name:     "hint penalty out of range",
+			json:     `{"id": "x", "hints": ["h"], "hintPolicy": {"penalty": 150}}`,
+			severity: SeverityError,
+			field:    "hintPolicy.penalty",
+		},
+		{
+			name:     "hint policy without hints",
+			json:     `{"id": "x", "hintPolicy": {"penalty": 10}}`,
+			severity: SeverityWarning,
+			field:    "hintPolicy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, issues := LintChallengeFile("x.json", []byte(tt.json))
+			if !hasIssue(issues, tt.severity, tt.field) {
+				t.Errorf("issues = %v, want %s %s", issueFields(issues), tt.severity, tt.field)
+			}
+		})
+	}
+}
+
+func TestLintChallengeFileInvalidJSON(t *testing.T) {
+	_, issues := LintChallengeFile("x.json", []byte("{\n  \"id\": \"x\",\n  \"title\" \"X\"\n}"))
+	if len(issues) != 1 || issues[0].Severity != SeverityError {
+		t.Fatalf("issues = %v, want one error", issueFields(issues))
+	}
+	if !strings.Contains(issues[0].Message, "line 3") {
+		t.Errorf("message = %q, want the line of the syntax error", issues[0].Message)
+	}
+}
+
+func TestLintChallengeFileKeepsOtherFieldsAfterTypeError(t *testing.T) {
+	challenge, _ := LintChallengeFile("x.json", []byte(`{"id": "x", "title": "X", "timeLimit": "one"}`))
+	if challenge.ID != "x" || challenge.Title != "X" {
+		t.Errorf("challenge = %+v, want id and title decoded despite the bad time limit", challenge)
+	}
+}
+
+func TestHasLiteralEscapes(t *testing.T) {
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{"int main() {\n}", false},
+		{`printf("a\n");`, false},
+		{`char c = '\n';`, false},
+		{`int main() {\n}`, true},
+		{`printf("a");\tint x;`, true},
+		{`puts("\"");\n`, true},
+	}
+	for _, tt := range tests {
+		if got := hasLiteralEscapes(tt.code); got != tt.want {
+			t.Errorf("hasLiteralEscapes(%q) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestLintChallengesDuplicateIDs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a/sum.json", "b/sum.json"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(validChallengeJSON), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report := LintChallenges(dir)
+	if report.Files != 2 {
+		t.Errorf("Files = %d, want 2", report.Files)
+	}
+	if report.Errors() != 2 || report.Warnings() != 0 {
+		t.Fatalf("issues = %v, want one duplicate ID error per file", issueFields(report.Issues))
+	}
+	for _, issue := range report.Issues {
+		if issue.Field != "id" || issue.ChallengeID != "sum" {
+			t.Errorf("issue = %+v, want a duplicate id error for sum", issue)
+		}
+	}
+}
+
+func TestLintChallengesLocaleCoverage(t *testing.T) {
+	dir := t.TempDir()
+	translated := strings.Replace(validChallengeJSON, `"difficulty"`,
+		`"translations": {"sw": {"title": "Jumla", "description": "Jumlisha."}}, "difficulty"`, 1)
+	files := map[string]string{
+		"one.json": strings.Replace(translated, `"sum"`, `"one"`, 1),
+		"two.json": strings.Replace(validChallengeJSON, `"sum"`, `"two"`, 1),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report := LintChallenges(dir)
+	if report.Errors() != 0 || report.Warnings() != 1 {
+		t.Fatalf("issues = %v, want one coverage warning", issueFields(report.Issues))
+	}
+	if issue := report.Issues[0]; issue.ChallengeID != "two" || issue.Field != "translations" {
+		t.Errorf("issue = %+v, want the missing sw translation of two", issue)
+	}
+}
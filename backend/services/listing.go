@@ -0,0 +1,118 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"sort"
+	"strings"
+
+	"strathlearn/backend/models"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ChallengeQuery filters the challenge listing. Empty fields do not filter.
+type ChallengeQuery struct {
+	Difficulties []string
+	// Tags must all be present on a challenge.
+	Tags     []string
+	Language string
+	// Status is "solved" or "unsolved" for the current user.
+	Status string
+	// Search terms must each appear in the title, description or tags.
+	Search string
+	Cursor string
+	Limit  int
+}
+
+type ChallengePage struct {
+	Challenges []models.ChallengeSummary `json:"challenges"`
+	Total      int                       `json:"total"`
+	NextCursor string                    `json:"nextCursor,omitempty"`
+}
+
+// ListChallenges filters challenges and returns one page of summaries in ID
+// order. The cursor is opaque to clients; it encodes the last ID returned.
+func ListChallenges(challenges map[string]models.Challenge, solved map[string]bool, q ChallengeQuery) (ChallengePage, error) {
+	after := ""
+	if q.Cursor != "" {
+		decoded, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+		if err != nil {
+			return ChallengePage{}, ErrInvalidCursor
+		}
+		after = string(decoded)
+	}
+
+	limit := pageLimit(q.Limit)
+
+	terms := strings.Fields(strings.ToLower(q.Search))
+	matches := make([]models.Challenge, 0, len(challenges))
+	for _, challenge := range challenges {
+		if matchesQuery(challenge, solved[challenge.ID], q, terms) {
+			matches = append(matches, challenge)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].ID < matches[j].ID })
+
+	page := ChallengePage{
+		Challenges: make([]models.ChallengeSummary, 0, limit),
+		Total:      len(matches),
+	}
+	start := sort.Search(len(matches), func(i int) bool { return matches[i].ID > after })
+	end := min(start+limit, len(matches))
+	for _, challenge := range matches[start:end] {
+		page.Challenges = append(page.Challenges, challenge.Summary(solved[challenge.ID]))
+	}
+	if end < len(matches) {
+		page.NextCursor = base64.RawURLEncoding.EncodeToString([]byte(matches[end-1].ID))
+	}
+	return page, nil
+}
+
+func matchesQuery(challenge models.Challenge, solved bool, q ChallengeQuery, terms []string) bool {
+	if len(q.Difficulties) > 0 && !containsFold(q.Difficulties, challenge.Difficulty) {
+		return false
+	}
+	for _, tag := range q.Tags {
+		if !containsFold(challenge.Tags, tag) {
+			return false
+		}
+	}
+	if q.Language != "" && !containsFold(challenge.SupportedLanguages(), q.Language) {
+		return false
+	}
+	switch q.Status {
+	case "solved":
+		if !solved {
+			return false
+		}
+	case "unsolved":
+		if solved {
+			return false
+		}
+	}
+
+	if len(terms) > 0 {
+		text := strings.ToLower(challenge.Title + "\n" + challenge.Description + "\n" + strings.Join(challenge.Tags, "\n"))
+		for _, term := range terms {
+			if !strings.Contains(text, term) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func containsFold(values []string, want string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, want) {
+			return true
+		}
+	}
+	return false
+}
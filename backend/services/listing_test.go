@@ -0,0 +1,207 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"strathlearn/backend/models"
+)
+
+func listingChallenges() map[string]models.Challenge {
+	list := []models.Challenge{
+		{ID: "arrays-1", Title: "Reverse an array", Difficulty: "easy", Tags: []string{"arrays"}},
+		{ID: "arrays-2", Title: "Rotate", Description: "Rotate an ARRAY in place.", Difficulty: "Medium", Tags: []string{"arrays", "pointers"}},
+		{ID: "hello", Title: "Hello, World!", Difficulty: "easy", Tags: []string{"basics"}, Languages: []string{"c", "cpp"}},
+		{ID: "lists", Title: "Linked lists", Difficulty: "hard", Tags: []string{"pointers", "structs"}},
+		{ID: "strings", Title: "Count words", Difficulty: "medium", Tags: []string{"Strings"}, Languages: []string{"python"}},
+	}
+	challenges := make(map[string]models.Challenge, len(list))
+	for _, challenge := range list {
+		challenges[challenge.ID] = challenge
+	}
+	return challenges
+}
+
+func pageIDs(page ChallengePage) []string {
+	ids := make([]string, 0, len(page.Challenges))
+	for _, summary := range page.Challenges {
+		ids = append(ids, summary.ID)
+	}
+	return ids
+}
+
+func TestListChallengesFilters(t *testing.T) {
+	solved := map[string]bool{"hello": true, "arrays-2": true}
+
+	tests := []struct {
+		name  string
+		query ChallengeQuery
+		want  []string
+	}{
+		{
+			name: "no filters",
+			want: []string{"arrays-1", "arrays-2", "hello", "lists", "strings"},
+		},
+		{
+			name:  "difficulty ignores case",
+			query: ChallengeQuery{Difficulties: []string{"MEDIUM"}},
+			want:  []string{"arrays-2", "strings"},
+		},
+		{
+			name:  "any of several difficulties",
+			query: ChallengeQuery{Difficulties: []string{"easy", "hard"}},
+			want:  []string{"arrays-1", "hello", "lists"},
+		},
+		{
+			name:  "every tag must match",
+			query: ChallengeQuery{Tags: []string{"arrays", "Pointers"}},
+			want:  []string{"arrays-2"},
+		},
+		{
+			name:  "language defaults to C",
+			query: ChallengeQuery{Language: "c"},
+			want:  []string{"arrays-1", "arrays-2", "hello", "lists"},
+		},
+		{
+			name:  "other language",
+			query: ChallengeQuery{Language: "Python"},
+			want:  []string{"strings"},
+		},
+		{
+			name:  "solved",
+			query: ChallengeQuery{Status: "solved"},
+			want:  []string{"arrays-2", "hello"},
+		},
+		{
+			name:  "unsolved",
+			query: ChallengeQuery{Status: "unsolved", Difficulties: []string{"easy"}},
+			want:  []string{"arrays-1"},
+		},
+		{
+			name:  "search matches title, description and tags",
+			query: ChallengeQuery{Search: "array"},
+			want:  []string{"arrays-1", "arrays-2"},
+		},
+		{
+			name:  "every search term must match",
+			query: ChallengeQuery{Search: "  rotate   PLACE "},
+			want:  []string{"arrays-2"},
+		},
+		{
+			name:  "search by tag",
+			query: ChallengeQuery{Search: "structs"},
+			want:  []string{"lists"},
+		},
+		{
+			name:  "nothing matches",
+			query: ChallengeQuery{Search: "graphs"},
+			want:  []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := ListChallenges(listingChallenges(), solved, tt.query)
+			if err != nil {
+				t.Fatalf("ListChallenges: %v", err)
+			}
+			if got := pageIDs(page); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("challenges = %v, want %v", got, tt.want)
+			}
+			if page.Total != len(tt.want) || page.NextCursor != "" {
+				t.Errorf("total, cursor = %d, %q, want %d and no cursor", page.Total, page.NextCursor, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestListChallengesSummaries(t *testing.T) {
+	page, err := ListChallenges(listingChallenges(), map[string]bool{"hello": true}, ChallengeQuery{Search: "hello"})
+	if err != nil {
+		t.Fatalf("ListChallenges: %v", err)
+	}
+	want := models.ChallengeSummary{
+		ID:         "hello",
+		Title:      "Hello, World!",
+		Difficulty: "easy",
+		Tags:       []string{"basics"},
+		Languages:  []string{"c", "cpp"},
+		Solved:     true,
+	}
+	if len(page.Challenges) != 1 || !reflect.DeepEqual(page.Challenges[0], want) {
+		t.Errorf("summaries = %+v, want %+v", page.Challenges, want)
+	}
+}
+
+func TestListChallengesPagination(t *testing.T) {
+	challenges := make(map[string]models.Challenge)
+	for i := 0; i < 45; i++ {
+		id := fmt.Sprintf("c%02d", i)
+		challenges[id] = models.Challenge{ID: id, Title: id}
+	}
+
+	var seen []string
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 5 {
+			t.Fatal("pagination did not finish")
+		}
+		page, err := ListChallenges(challenges, nil, ChallengeQuery{Cursor: cursor, Limit: 20})
+		if err != nil {
+			t.Fatalf("ListChallenges: %v", err)
+		}
+		if page.Total != 45 {
+			t.Errorf("total = %d, want 45", page.Total)
+		}
+		seen = append(seen, pageIDs(page)...)
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	if len(seen) != 45 {
+		t.Fatalf("saw %d challenges over all pages, want 45", len(seen))
+	}
+	for i, id := range seen {
+		if want := fmt.Sprintf("c%02d", i); id != want {
+			t.Fatalf("challenge %d = %s, want %s", i, id, want)
+		}
+	}
+
+	page, _ := ListChallenges(challenges, nil, ChallengeQuery{})
+	if len(page.Challenges) != DefaultPageSize {
+		t.Errorf("default page size = %d, want %d", len(page.Challenges), DefaultPageSize)
+	}
+	page, _ = ListChallenges(challenges, nil, ChallengeQuery{Limit: MaxPageSize + 50})
+	if len(page.Challenges) != 45 {
+		t.Errorf("oversized limit returned %d challenges, want all 45", len(page.Challenges))
+	}
+}
+
+func TestListChallengesCursorSurvivesRemovals(t *testing.T) {
+	challenges := listingChallenges()
+	first, err := ListChallenges(challenges, nil, ChallengeQuery{Limit: 2})
+	if err != nil {
+		t.Fatalf("ListChallenges: %v", err)
+	}
+
+	// The challenge the cursor points at disappears before the next page.
+	delete(challenges, "arrays-2")
+	next, err := ListChallenges(challenges, nil, ChallengeQuery{Limit: 2, Cursor: first.NextCursor})
+	if err != nil {
+		t.Fatalf("ListChallenges: %v", err)
+	}
+	if got := pageIDs(next); !reflect.DeepEqual(got, []string{"hello", "lists"}) {
+		t.Errorf("next page = %v, want [hello lists]", got)
+	}
+}
+
+func TestListChallengesInvalidCursor(t *testing.T) {
+	_, err := ListChallenges(listingChallenges(), nil, ChallengeQuery{Cursor: "not base64!"})
+	if !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("error = %v, want ErrInvalidCursor", err)
+	}
+}
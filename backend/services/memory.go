@@ -0,0 +1,680 @@
+package services
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"strathlearn/backend/db"
+	"strathlearn/backend/models"
+)
+
+// The Memory stores are in-memory repositories for exercising handler and
+// runner logic without a database. They are safe for concurrent use, but
+// the submission and profile stores do not model give-ups.
+
+// MemoryChallengeStore is a writable ChallengeRepository. Like the database
+// store it records a version whenever a challenge is added or changed.
+type MemoryChallengeStore struct {
+	mu         sync.RWMutex
+	challenges map[string]models.Challenge
+	authors    map[string]string
+	versions   VersionRepository
+}
+
+func NewMemoryChallengeStore(versions VersionRepository, challenges ...models.Challenge) *MemoryChallengeStore {
+	s := &MemoryChallengeStore{
+		challenges: make(map[string]models.Challenge),
+		authors:    make(map[string]string),
+		versions:   versions,
+	}
+	for _, challenge := range challenges {
+		s.put(challenge)
+	}
+	return s
+}
+
+// put stores challenge and records its version. The caller holds s.mu.
+func (s *MemoryChallengeStore) put(challenge models.Challenge) error {
+	s.challenges[challenge.ID] = challenge
+	_, err := s.versions.Record(challenge)
+	return err
+}
+
+func (s *MemoryChallengeStore) Get(id string) (models.Challenge, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	challenge, ok := s.challenges[id]
+	return challenge, ok
+}
+
+func (s *MemoryChallengeStore) All() map[string]models.Challenge {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	all := make(map[string]models.Challenge, len(s.challenges))
+	for id, challenge := range s.challenges {
+		all[id] = challenge
+	}
+	return all
+}
+
+func (s *MemoryChallengeStore) Create(challenge models.Challenge, authorID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.challenges[challenge.ID]; ok {
+		return ErrChallengeExists
+	}
+	s.authors[challenge.ID] = authorID
+	return s.put(challenge)
+}
+
+func (s *MemoryChallengeStore) AuthorOf(id string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, ok := s.challenges[id]; !ok {
+		return "", ErrChallengeNotFound
+	}
+	return s.authors[id], nil
+}
+
+func (s *MemoryChallengeStore) Update(challenge models.Challenge) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.challenges[challenge.ID]; !ok {
+		return ErrChallengeNotFound
+	}
+	return s.put(challenge)
+}
+
+func (s *MemoryChallengeStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.challenges[id]; !ok {
+		return ErrChallengeNotFound
+	}
+	delete(s.challenges, id)
+	delete(s.authors, id)
+	return nil
+}
+
+func (s *MemoryChallengeStore) SaveTestCase(challengeID string, tc models.TestCase) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	challenge, ok := s.challenges[challengeID]
+	if !ok {
+		return ErrChallengeNotFound
+	}
+	testCases := make([]models.TestCase, 0, len(challenge.TestCases)+1)
+	replaced := false
+	for _, existing := range challenge.TestCases {
+		if existing.ID == tc.ID {
+			existing = tc
+			replaced = true
+		}
+		testCases = append(testCases, existing)
+	}
+	if !replaced {
+		testCases = append(testCases, tc)
+	}
+	challenge.TestCases = testCases
+	return s.put(challenge)
+}
+
+func (s *MemoryChallengeStore) DeleteTestCase(challengeID, testID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	challenge, ok := s.challenges[challengeID]
+	if !ok {
+		return ErrChallengeNotFound
+	}
+	testCases := make([]models.TestCase, 0, len(challenge.TestCases))
+	for _, existing := range challenge.TestCases {
+		if existing.ID != testID {
+			testCases = append(testCases, existing)
+		}
+	}
+	if len(testCases) == len(challenge.TestCases) {
+		return ErrTestCaseNotFound
+	}
+	challenge.TestCases = testCases
+	return s.put(challenge)
+}
+
+// MemorySubmissionStore is a SubmissionRepository backed by a slice.
+type MemorySubmissionStore struct {
+	mu          sync.RWMutex
+	submissions []db.Submission
+}
+
+func NewMemorySubmissionStore() *MemorySubmissionStore {
+	return &MemorySubmissionStore{}
+}
+
+func (s *MemorySubmissionStore) Create(submission *db.Submission) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if submission.ID == "" {
+		submission.ID = uuid.NewString()
+	}
+	if submission.CreatedAt.IsZero() {
+		submission.CreatedAt = time.Now()
+	}
+	submission.UpdatedAt = submission.CreatedAt
+	for i := range submission.Results {
+		submission.Results[i].SubmissionID = submission.ID
+	}
+	s.submissions = append(s.submissions, *submission)
+	return nil
+}
+
+func (s *MemorySubmissionStore) Get(id string) (db.Submission, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, submission := range s.submissions {
+		if submission.ID == id {
+			return submission, true, nil
+		}
+	}
+	return db.Submission{}, false, nil
+}
+
+func (s *MemorySubmissionStore) Results(submissionID string) ([]models.TestResult, error) {
+	submission, _, _ := s.Get(submissionID)
+	results := make([]models.TestResult, 0, len(submission.Results))
+	for _, row := range submission.Results {
+		results = append(results, row.ToModel())
+	}
+	return results, nil
+}
+
+func (s *MemorySubmissionStore) List(q SubmissionQuery) (SubmissionPage, error) {
+	var after time.Time
+	var afterID string
+	if q.Cursor != "" {
+		var err error
+		if after, afterID, err = decodeSubmissionCursor(q.Cursor); err != nil {
+			return SubmissionPage{}, err
+		}
+	}
+
+	s.mu.RLock()
+	var rows []db.Submission
+	for _, submission := range s.submissions {
+		switch {
+		case submission.UserID != q.UserID,
+			q.ChallengeID != "" && submission.ChallengeID != q.ChallengeID,
+			q.Verdict != "" && submission.Verdict != q.Verdict,
+			!q.From.IsZero() && submission.CreatedAt.Before(q.From),
+			!q.To.IsZero() && !submission.CreatedAt.Before(q.To),
+			q.Cursor != "" && !newerFirst(after, afterID, submission.CreatedAt, submission.ID):
+			continue
+		}
+		rows = append(rows, submission)
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(rows, func(i, j int) bool {
+		return newerFirst(rows[i].CreatedAt, rows[i].ID, rows[j].CreatedAt, rows[j].ID)
+	})
+	limit := pageLimit(q.Limit)
+	return submissionPage(rows[:min(len(rows), limit+1)], limit), nil
+}
+
+// newerFirst reports whether submission a sorts before b in history order.
+func newerFirst(aTime time.Time, aID string, bTime time.Time, bID string) bool {
+	if !aTime.Equal(bTime) {
+		return aTime.After(bTime)
+	}
+	return aID > bID
+}
+
+func (s *MemorySubmissionStore) ForChallenge(challengeID string, onlyAccepted bool) ([]db.Submission, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var rows []db.Submission
+	for _, submission := range s.submissions {
+		if submission.ChallengeID == challengeID && submission.Code != "" &&
+			(!onlyAccepted || submission.Verdict == models.VerdictAccepted) {
+			rows = append(rows, submission)
+		}
+	}
+	return rows, nil
+}
+
+func (s *MemorySubmissionStore) UpdateOutcome(submission db.Submission) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.submissions {
+		if s.submissions[i].ID == submission.ID {
+			for j := range submission.Results {
+				submission.Results[j].SubmissionID = submission.ID
+			}
+			s.submissions[i] = submission
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *MemorySubmissionStore) DailyActivity(userID string, from, to time.Time) ([]DailyActivity, error) {
+	type key struct {
+		date        time.Time
+		challengeID string
+	}
+	counts := make(map[key]int)
+
+	s.mu.RLock()
+	for _, submission := range s.submissions {
+		if submission.UserID != userID || submission.CreatedAt.Before(from) || submission.CreatedAt.After(to) {
+			continue
+		}
+		y, m, d := submission.CreatedAt.Date()
+		counts[key{time.Date(y, m, d, 0, 0, 0, 0, time.UTC), submission.ChallengeID}]++
+	}
+	s.mu.RUnlock()
+
+	activity := make([]DailyActivity, 0, len(counts))
+	for k, count := range counts {
+		activity = append(activity, DailyActivity{Date: k.date, ChallengeID: k.challengeID, Count: count})
+	}
+	sort.Slice(activity, func(i, j int) bool {
+		if !activity[i].Date.Equal(activity[j].Date) {
+			return activity[i].Date.Before(activity[j].Date)
+		}
+		return activity[i].ChallengeID < activity[j].ChallengeID
+	})
+	return activity, nil
+}
+
+// oldestFirst returns a user's submissions in the order they were made.
+func (s *MemorySubmissionStore) oldestFirst(userID string) []db.Submission {
+	s.mu.RLock()
+	var rows []db.Submission
+	for _, submission := range s.submissions {
+		if submission.UserID == userID {
+			rows = append(rows, submission)
+		}
+	}
+	s.mu.RUnlock()
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].CreatedAt.Before(rows[j].CreatedAt) })
+	return rows
+}
+
+// setTotals recounts the submission totals on a user's profile.
+func (s *MemorySubmissionStore) setTotals(profile *db.Profile) {
+	profile.TotalSubmissions = 0
+	profile.FirstSubmissionAt = nil
+	profile.LastSubmissionAt = nil
+	s.mu.RLock()
+	for _, submission := range s.submissions {
+		if submission.UserID != profile.UserId {
+			continue
+		}
+		profile.TotalSubmissions++
+		createdAt := submission.CreatedAt
+		if profile.FirstSubmissionAt == nil || createdAt.Before(*profile.FirstSubmissionAt) {
+			profile.FirstSubmissionAt = &createdAt
+		}
+		if profile.LastSubmissionAt == nil || createdAt.After(*profile.LastSubmissionAt) {
+			profile.LastSubmissionAt = &createdAt
+		}
+	}
+	s.mu.RUnlock()
+
+	profile.TotalScore = 0
+	profile.ChallengesCompleted = 0
+	for _, score := range s.bestScores()[profile.UserId] {
+		profile.TotalScore += score.BestScore
+		if score.Solved() {
+			profile.ChallengesCompleted++
+		}
+	}
+}
+
+func (s *MemorySubmissionStore) FailedAttempts(userID, challengeID string) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	failed := 0
+	for _, submission := range s.submissions {
+		if submission.UserID == userID && submission.ChallengeID == challengeID &&
+			submission.Verdict != "" && submission.Verdict != models.VerdictAccepted {
+			failed++
+		}
+	}
+	return failed, nil
+}
+
+func (s *MemorySubmissionStore) BestScores(userID string) ([]BestScore, error) {
+	return s.bestScores()[userID], nil
+}
+
+func (s *MemorySubmissionStore) Leaderboard(limit int) ([]LeaderboardRow, error) {
+	rows := s.leaderboard()
+	return rows[:min(len(rows), limit)], nil
+}
+
+func (s *MemorySubmissionStore) Rank(userID string) (int, error) {
+	for _, row := range s.leaderboard() {
+		if row.UserID == userID {
+			return row.Rank, nil
+		}
+	}
+	return 0, nil
+}
+
+// leaderboard ranks every user the way DBSubmissionStore does.
+func (s *MemorySubmissionStore) leaderboard() []LeaderboardRow {
+	var rows []LeaderboardRow
+	for userID, scores := range s.bestScores() {
+		row := LeaderboardRow{UserID: userID, ChallengesAttempted: len(scores)}
+		for _, score := range scores {
+			row.TotalScore += score.BestScore
+			if score.Solved() {
+				row.ChallengesCompleted++
+			}
+		}
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if a.TotalScore != b.TotalScore {
+			return a.TotalScore > b.TotalScore
+		}
+		if a.ChallengesCompleted != b.ChallengesCompleted {
+			return a.ChallengesCompleted > b.ChallengesCompleted
+		}
+		return a.UserID < b.UserID
+	})
+	for i := range rows {
+		rows[i].Rank = i + 1
+		if i > 0 && rows[i].TotalScore == rows[i-1].TotalScore &&
+			rows[i].ChallengesCompleted == rows[i-1].ChallengesCompleted {
+			rows[i].Rank = rows[i-1].Rank
+		}
+	}
+	return rows
+}
+
+// bestScores groups the best score of every user on every challenge.
+func (s *MemorySubmissionStore) bestScores() map[string][]BestScore {
+	type key struct{ userID, challengeID string }
+	best := make(map[key]BestScore)
+
+	s.mu.RLock()
+	for _, submission := range s.submissions {
+		if submission.ChallengeID == "" {
+			continue
+		}
+		k := key{submission.UserID, submission.ChallengeID}
+		score := best[k]
+		score.ChallengeID = submission.ChallengeID
+		score.BestScore = max(score.BestScore, submission.Score)
+		score.RawScore = max(score.RawScore, submission.Score+submission.HintPenalty)
+		score.MaxScore = max(score.MaxScore, submission.MaxScore)
+		best[k] = score
+	}
+	s.mu.RUnlock()
+
+	byUser := make(map[string][]BestScore)
+	for k, score := range best {
+		byUser[k.userID] = append(byUser[k.userID], score)
+	}
+	return byUser
+}
+
+// MemoryProfileStore is a ProfileRepository backed by a map. It totals
+// users' submissions from the given submission store.
+type MemoryProfileStore struct {
+	mu          sync.RWMutex
+	submissions *MemorySubmissionStore
+	profiles    map[string]db.Profile
+	solves      map[string]map[string]string // user ID -> challenge ID -> difficulty
+}
+
+func NewMemoryProfileStore(submissions *MemorySubmissionStore) *MemoryProfileStore {
+	return &MemoryProfileStore{
+		submissions: submissions,
+		profiles:    make(map[string]db.Profile),
+		solves:      make(map[string]map[string]string),
+	}
+}
+
+func (s *MemoryProfileStore) Get(userID string) (db.Profile, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	profile, ok := s.profiles[userID]
+	return profile, ok, nil
+}
+
+func (s *MemoryProfileStore) SetLocale(userID, locale string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	profile, ok := s.profiles[userID]
+	if !ok {
+		profile = db.Profile{UserId: userID, CreatedAt: time.Now()}
+	}
+	profile.Locale = locale
+	profile.UpdatedAt = time.Now()
+	s.profiles[userID] = profile
+	return nil
+}
+
+func (s *MemoryProfileStore) RecordSubmission(submission db.Submission, difficulty string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	firstSolve := s.record(submission, difficulty)
+	profile := s.profiles[submission.UserID]
+	s.submissions.setTotals(&profile)
+	s.profiles[submission.UserID] = profile
+	return firstSolve, nil
+}
+
+func (s *MemoryProfileStore) Rebuild(userID string, difficulty func(challengeID string) string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.solves, userID)
+	profile, ok := s.profiles[userID]
+	if ok {
+		profile.ChallengesSolved = 0
+		profile.Streaks = 0
+		profile.LongestStreak = 0
+		s.profiles[userID] = profile
+	}
+	submissions := s.submissions.oldestFirst(userID)
+	for _, submission := range submissions {
+		s.record(submission, difficulty(submission.ChallengeID))
+	}
+	if profile, ok := s.profiles[userID]; ok {
+		s.submissions.setTotals(&profile)
+		s.profiles[userID] = profile
+	}
+	return nil
+}
+
+// record applies one submission to its author's profile. The caller holds
+// s.mu.
+func (s *MemoryProfileStore) record(submission db.Submission, difficulty string) bool {
+	profile, ok := s.profiles[submission.UserID]
+	if !ok {
+		profile = db.Profile{UserId: submission.UserID, CreatedAt: time.Now()}
+	}
+	advanceStreak(&profile, submission.CreatedAt)
+
+	solves := s.solves[submission.UserID]
+	if solves == nil {
+		solves = make(map[string]string)
+		s.solves[submission.UserID] = solves
+	}
+	_, solvedBefore := solves[submission.ChallengeID]
+	firstSolve := isAccepted(submission) && !solvedBefore
+	if firstSolve {
+		solves[submission.ChallengeID] = difficulty
+		profile.ChallengesSolved++
+	}
+	profile.UpdatedAt = time.Now()
+	s.profiles[submission.UserID] = profile
+	return firstSolve
+}
+
+func (s *MemoryProfileStore) SolvedByDifficulty(userID string) (map[string]int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	solved := make(map[string]int)
+	for _, difficulty := range s.solves[userID] {
+		solved[difficulty]++
+	}
+	return solved, nil
+}
+
+// MemoryHintStore is a HintRepository backed by a slice.
+type MemoryHintStore struct {
+	mu      sync.RWMutex
+	unlocks []db.HintUnlock
+}
+
+func NewMemoryHintStore() *MemoryHintStore {
+	return &MemoryHintStore{}
+}
+
+func (s *MemoryHintStore) Unlocks(userID, challengeID string) ([]db.HintUnlock, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var unlocks []db.HintUnlock
+	for _, unlock := range s.unlocks {
+		if unlock.UserID == userID && unlock.ChallengeID == challengeID {
+			unlocks = append(unlocks, unlock)
+		}
+	}
+	sort.Slice(unlocks, func(i, j int) bool { return unlocks[i].HintIndex < unlocks[j].HintIndex })
+	return unlocks, nil
+}
+
+func (s *MemoryHintStore) Unlock(unlock db.HintUnlock) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.unlocks {
+		if existing.UserID == unlock.UserID && existing.ChallengeID == unlock.ChallengeID &&
+			existing.HintIndex == unlock.HintIndex {
+			return nil
+		}
+	}
+	unlock.ID = uint(len(s.unlocks) + 1)
+	s.unlocks = append(s.unlocks, unlock)
+	return nil
+}
+
+func (s *MemoryHintStore) Usage(userID string) (map[string]int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	usage := make(map[string]int)
+	for _, unlock := range s.unlocks {
+		if unlock.UserID == userID {
+			usage[unlock.ChallengeID]++
+		}
+	}
+	return usage, nil
+}
+
+// MemorySolutionStore is a SolutionRepository backed by maps.
+type MemorySolutionStore struct {
+	mu      sync.RWMutex
+	giveUps map[string]map[string]bool // user ID -> challenge ID
+	reveals []db.SolutionReveal
+}
+
+func NewMemorySolutionStore() *MemorySolutionStore {
+	return &MemorySolutionStore{giveUps: make(map[string]map[string]bool)}
+}
+
+func (s *MemorySolutionStore) GiveUp(userID, challengeID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.giveUps[userID] == nil {
+		s.giveUps[userID] = make(map[string]bool)
+	}
+	s.giveUps[userID][challengeID] = true
+	return nil
+}
+
+func (s *MemorySolutionStore) HasGivenUp(userID, challengeID string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.giveUps[userID][challengeID], nil
+}
+
+func (s *MemorySolutionStore) RecordReveal(userID, challengeID, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reveals = append(s.reveals, db.SolutionReveal{
+		ID:          uint(len(s.reveals) + 1),
+		UserID:      userID,
+		ChallengeID: challengeID,
+		Reason:      reason,
+		CreatedAt:   time.Now(),
+	})
+	return nil
+}
+
+func (s *MemorySolutionStore) Reveals(challengeID string) ([]db.SolutionReveal, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var reveals []db.SolutionReveal
+	for i := len(s.reveals) - 1; i >= 0; i-- {
+		if s.reveals[i].ChallengeID == challengeID {
+			reveals = append(reveals, s.reveals[i])
+		}
+	}
+	return reveals, nil
+}
+
+// MemoryVersionStore is a VersionRepository backed by a map.
+type MemoryVersionStore struct {
+	mu       sync.Mutex
+	versions map[string][]models.Challenge // challenge ID -> versions, oldest first
+	hashes   map[string]string             // challenge ID -> hash of the latest version
+}
+
+func NewMemoryVersionStore() *MemoryVersionStore {
+	return &MemoryVersionStore{
+		versions: make(map[string][]models.Challenge),
+		hashes:   make(map[string]string),
+	}
+}
+
+func (s *MemoryVersionStore) Record(challenge models.Challenge) (int, error) {
+	hash, err := challengeHash(challenge)
+	if err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.versions[challenge.ID]) > 0 && s.hashes[challenge.ID] == hash {
+		return len(s.versions[challenge.ID]), nil
+	}
+	challenge.FilePath = ""
+	s.versions[challenge.ID] = append(s.versions[challenge.ID], challenge)
+	s.hashes[challenge.ID] = hash
+	return len(s.versions[challenge.ID]), nil
+}
+
+func (s *MemoryVersionStore) Current(challenge models.Challenge) (int, error) {
+	s.mu.Lock()
+	latest := len(s.versions[challenge.ID])
+	s.mu.Unlock()
+	if latest > 0 {
+		return latest, nil
+	}
+	return s.Record(challenge)
+}
+
+func (s *MemoryVersionStore) Get(challengeID string, version int) (models.Challenge, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	versions := s.versions[challengeID]
+	if version < 1 || version > len(versions) {
+		return models.Challenge{}, false
+	}
+	return versions[version-1], true
+}
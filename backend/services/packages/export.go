@@ -0,0 +1,150 @@
+package packages
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"strathlearn/backend/models"
+)
+
+// Export writes challenges as a StrathLearn zip package. Each challenge gets
+// a directory holding challenge.json and its tests as <id>.in and <id>.out
+// files, so the package can be reviewed and edited with ordinary tools and
+// loaded by another deployment with Import.
+func Export(w io.Writer, challenges []models.Challenge) error {
+	sort.Slice(challenges, func(i, j int) bool { return challenges[i].ID < challenges[j].ID })
+
+	zw := zip.NewWriter(w)
+	for _, challenge := range challenges {
+		if err := exportChallenge(zw, challenge); err != nil {
+			zw.Close()
+			return fmt.Errorf("%s: %w", challenge.ID, err)
+		}
+	}
+	return zw.Close()
+}
+
+func exportChallenge(zw *zip.Writer, challenge models.Challenge) error {
+	if !safeName(challenge.ID) {
+		return fmt.Errorf("challenge ID cannot be used as a directory name")
+	}
+	dir := challenge.ID
+
+	// Inline generator files so the package does not depend on paths on
+	// this machine.
+	if gen := challenge.Generator; gen != nil && gen.File != "" {
+		file := gen.File
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(filepath.Dir(challenge.FilePath), file)
+		}
+		source, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("error reading generator: %w", err)
+		}
+		inlined := *gen
+		inlined.Source = string(source)
+		inlined.File = ""
+		challenge.Generator = &inlined
+	}
+
+	tests := challenge.TestCases
+	challenge.TestCases = make([]models.TestCase, len(tests))
+	for i, tc := range tests {
+		if !safeName(tc.ID) {
+			return fmt.Errorf("test case ID %q cannot be used as a file name", tc.ID)
+		}
+		challenge.TestCases[i] = models.TestCase{ID: tc.ID, Hidden: tc.Hidden}
+		if err := writeZipFile(zw, path.Join(dir, "tests", tc.ID+".in"), tc.Input); err != nil {
+			return err
+		}
+		if err := writeZipFile(zw, path.Join(dir, "tests", tc.ID+".out"), tc.ExpectedOutput); err != nil {
+			return err
+		}
+	}
+
+	data, err := MarshalChallenge(challenge)
+	if err != nil {
+		return err
+	}
+	return writeZipFile(zw, path.Join(dir, "challenge.json"), string(data))
+}
+
+func writeZipFile(zw *zip.Writer, name, content string) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(f, content)
+	return err
+}
+
+func safeName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
+
+// importStrathLearn reads a package written by Export. A package holding a
+// single challenge may have been unwrapped by open, leaving challenge.json at
+// the root.
+func importStrathLearn(fsys fs.FS) ([]Result, error) {
+	if exists(fsys, "challenge.json") {
+		result, err := importStrathLearnChallenge(fsys, ".")
+		if err != nil {
+			return nil, err
+		}
+		return []Result{result}, nil
+	}
+
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	var results []Result
+	for _, entry := range entries {
+		if !entry.IsDir() || !exists(fsys, path.Join(entry.Name(), "challenge.json")) {
+			continue
+		}
+		result, err := importStrathLearnChallenge(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		results = append(results, result)
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("not a StrathLearn package: no challenge.json found")
+	}
+	return results, nil
+}
+
+func importStrathLearnChallenge(fsys fs.FS, dir string) (Result, error) {
+	var result Result
+	data, err := fs.ReadFile(fsys, path.Join(dir, "challenge.json"))
+	if err != nil {
+		return result, err
+	}
+	if err := json.Unmarshal(data, &result.Challenge); err != nil {
+		return result, fmt.Errorf("challenge.json: %w", err)
+	}
+
+	for i, tc := range result.Challenge.TestCases {
+		in := path.Join(dir, "tests", tc.ID+".in")
+		out := path.Join(dir, "tests", tc.ID+".out")
+		if !exists(fsys, in) {
+			// Tests may also be kept inline in challenge.json.
+			continue
+		}
+		test, err := readTest(fsys, tc.ID, in, out, tc.Hidden)
+		if err != nil {
+			return result, err
+		}
+		result.Challenge.TestCases[i] = test
+	}
+	return result, nil
+}
@@ -0,0 +1,203 @@
+package packages
+
+import (
+	"fmt"
+	"io/fs"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"strathlearn/backend/models"
+)
+
+// defaultMemoryLimit is used when a package does not set one. Kattis itself
+// defaults to 2 GB, which is far more than our runners allow.
+const defaultMemoryLimit = 256
+
+type kattisProblem struct {
+	Name       interface{} `yaml:"name"`
+	Validation string      `yaml:"validation"`
+	Limits     struct {
+		TimeLimit float64 `yaml:"time_limit"`
+		Memory    int     `yaml:"memory"`
+	} `yaml:"limits"`
+}
+
+type kattisTestData struct {
+	AcceptScore *float64 `yaml:"accept_score"`
+}
+
+// importKattis converts a Kattis problem package directory. Secret test
+// groups in subdirectories of data/secret become subtasks.
+func importKattis(fsys fs.FS, name string) (Result, error) {
+	result := Result{Challenge: models.Challenge{
+		SchemaVersion: models.ChallengeSchemaVersion,
+		ID:            name,
+		Title:         name,
+		TimeLimit:     1,
+		MemoryLimit:   defaultMemoryLimit,
+		Hints:         []string{},
+	}}
+	ch := &result.Challenge
+
+	raw, err := readText(fsys, "problem.yaml")
+	if err != nil {
+		return result, fmt.Errorf("not a Kattis package: %w", err)
+	}
+	var problem kattisProblem
+	if err := yaml.Unmarshal([]byte(raw), &problem); err != nil {
+		return result, fmt.Errorf("problem.yaml: %w", err)
+	}
+
+	switch title := problem.Name.(type) {
+	case string:
+		ch.Title = title
+	case map[string]interface{}:
+		if en, ok := title["en"].(string); ok {
+			ch.Title = en
+		} else {
+			for _, v := range title {
+				ch.Title = fmt.Sprint(v)
+				break
+			}
+		}
+	}
+
+	timeLimit := problem.Limits.TimeLimit
+	if text, err := readText(fsys, ".timelimit"); err == nil {
+		if t, err := strconv.ParseFloat(strings.TrimSpace(text), 64); err == nil {
+			timeLimit = t
+		}
+	}
+	if timeLimit > 0 {
+		ch.TimeLimit = int(math.Ceil(timeLimit))
+	} else {
+		result.warn("no time limit in package, using %d second", ch.TimeLimit)
+	}
+	if problem.Limits.Memory > 0 {
+		ch.MemoryLimit = problem.Limits.Memory
+	}
+
+	if strings.Contains(problem.Validation, "custom") || exists(fsys, "output_validators") {
+		result.warn("custom output validator is not supported; output will be compared exactly")
+	}
+
+	ch.Description = kattisStatement(fsys, &result)
+
+	samples, err := testFiles(fsys, "data/sample", ".ans")
+	if err != nil && exists(fsys, "data/sample") {
+		return result, err
+	}
+	for _, pair := range samples {
+		tc, err := readTest(fsys, "sample-"+testName(pair[0]), pair[0], pair[1], false)
+		if err != nil {
+			return result, err
+		}
+		ch.TestCases = append(ch.TestCases, tc)
+	}
+
+	if err := kattisSecret(fsys, "data/secret", "secret", &result); err != nil {
+		return result, err
+	}
+
+	if entries, err := fs.ReadDir(fsys, "submissions/accepted"); err == nil {
+		for _, entry := range entries {
+			file := path.Join("submissions/accepted", entry.Name())
+			if entry.IsDir() || !isCSource(file) {
+				continue
+			}
+			source, err := readText(fsys, file)
+			if err != nil {
+				return result, err
+			}
+			ch.Solutions = append(ch.Solutions, source)
+		}
+	}
+	if len(ch.Solutions) == 0 {
+		result.warn("no accepted C submission to use as a reference solution")
+	}
+
+	return result, nil
+}
+
+// kattisSecret reads the secret tests in dir. Each subdirectory is a test
+// group and becomes a subtask scored by its accept_score.
+func kattisSecret(fsys fs.FS, dir, prefix string, result *Result) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil
+	}
+
+	pairs, err := testFiles(fsys, dir, ".ans")
+	if err != nil {
+		return err
+	}
+	var ids []string
+	for _, pair := range pairs {
+		id := prefix + "-" + testName(pair[0])
+		tc, err := readTest(fsys, id, pair[0], pair[1], true)
+		if err != nil {
+			return err
+		}
+		result.Challenge.TestCases = append(result.Challenge.TestCases, tc)
+		ids = append(ids, id)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			sub := path.Join(dir, entry.Name())
+			if err := kattisSecret(fsys, sub, prefix+"-"+entry.Name(), result); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Top-level secret tests only need a subtask of their own when groups
+	// exist; otherwise every test is worth a point already.
+	grouped := dir != "data/secret"
+	if len(ids) == 0 || (!grouped && len(result.Challenge.Subtasks) == 0) {
+		return nil
+	}
+	points := 1
+	if raw, err := readText(fsys, path.Join(dir, "testdata.yaml")); err == nil {
+		var data kattisTestData
+		if yaml.Unmarshal([]byte(raw), &data) == nil && data.AcceptScore != nil {
+			points = int(math.Round(*data.AcceptScore))
+		}
+	}
+	result.Challenge.Subtasks = append(result.Challenge.Subtasks, models.Subtask{
+		ID:        prefix,
+		Name:      strings.TrimPrefix(dir, "data/"),
+		Points:    points,
+		TestCases: ids,
+	})
+	return nil
+}
+
+// kattisStatement prefers an English Markdown statement and falls back to
+// LaTeX. Both the legacy problem_statement and newer statement directories
+// are searched.
+func kattisStatement(fsys fs.FS, result *Result) string {
+	for _, dir := range []string{"statement", "problem_statement"} {
+		for _, file := range []string{"problem.en.md", "problem.md"} {
+			if text, err := readText(fsys, path.Join(dir, file)); err == nil {
+				return strings.TrimSpace(text)
+			}
+		}
+		for _, file := range []string{"problem.en.tex", "problem.tex"} {
+			if text, err := readText(fsys, path.Join(dir, file)); err == nil {
+				result.warn("statement converted from LaTeX; review the description")
+				return texToMarkdown(text)
+			}
+		}
+	}
+	result.warn("no problem statement found")
+	return ""
+}
+
+func testName(file string) string {
+	return strings.TrimSuffix(path.Base(file), path.Ext(file))
+}
@@ -0,0 +1,186 @@
+// Package packages converts problem packages from other judges into
+// challenges and writes challenges out as portable zip packages.
+package packages
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"strathlearn/backend/models"
+)
+
+// Result is a converted challenge together with anything that could not be
+// carried over exactly, such as a custom checker.
+type Result struct {
+	Challenge models.Challenge
+	Warnings  []string
+}
+
+func (r *Result) warn(format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// Formats lists the package formats Import understands.
+var Formats = []string{"polygon", "kattis", "strathlearn"}
+
+// Import reads the package at path, which may be a directory or a zip file.
+// Polygon and Kattis packages hold one problem; StrathLearn packages may hold
+// several.
+func Import(format, path string) ([]Result, error) {
+	fsys, name, closer, err := open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer closer()
+
+	switch format {
+	case "polygon":
+		result, err := importPolygon(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		return []Result{result}, nil
+	case "kattis":
+		result, err := importKattis(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		return []Result{result}, nil
+	case "strathlearn":
+		return importStrathLearn(fsys)
+	default:
+		return nil, fmt.Errorf("unknown package format %q (want %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// open returns the package contents as a file system. Zip files that wrap
+// everything in a single top-level directory are unwrapped.
+func open(p string) (fs.FS, string, func() error, error) {
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	name := strings.TrimSuffix(filepath.Base(p), filepath.Ext(p))
+	if info.IsDir() {
+		return os.DirFS(p), filepath.Base(p), func() error { return nil }, nil
+	}
+
+	zr, err := zip.OpenReader(p)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("error opening %s: %w", p, err)
+	}
+	var fsys fs.FS = zr
+	entries, err := fs.ReadDir(zr, ".")
+	if err == nil && len(entries) == 1 && entries[0].IsDir() {
+		name = entries[0].Name()
+		fsys, _ = fs.Sub(zr, name)
+	}
+	return fsys, name, zr.Close, nil
+}
+
+// MarshalChallenge encodes a challenge the way challenge files are written:
+// indented, and without escaping the < and > that fill C source code.
+func MarshalChallenge(challenge models.Challenge) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(challenge); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func readText(fsys fs.FS, name string) (string, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return "", err
+	}
+	return strings.ReplaceAll(string(data), "\r\n", "\n"), nil
+}
+
+func exists(fsys fs.FS, name string) bool {
+	_, err := fs.Stat(fsys, name)
+	return err == nil
+}
+
+// isCSource reports whether a solution can be used as a reference solution;
+// every runner compiles submissions as C.
+func isCSource(name string) bool {
+	return strings.EqualFold(path.Ext(name), ".c")
+}
+
+var (
+	texSection   = regexp.MustCompile(`\\(?:sub)*section\*?\{([^}]*)\}`)
+	texFormat    = regexp.MustCompile(`\\(?:textbf|textit|emph|texttt)\{([^}]*)\}`)
+	texProblem   = regexp.MustCompile(`(?m)^\s*\\(?:begin|end)\{problem\}.*$`)
+	texEnv       = regexp.MustCompile(`(?m)^\s*\\(?:begin|end)\{(?:itemize|enumerate)\}\s*$`)
+	texItem      = regexp.MustCompile(`(?m)^(\s*)\\item\s*`)
+	texBlankRuns = regexp.MustCompile(`\n{3,}`)
+)
+
+// texToMarkdown does a best-effort conversion of the LaTeX used in problem
+// statements. Maths is left in $...$ form, which the frontend renders.
+func texToMarkdown(tex string) string {
+	md := texProblem.ReplaceAllString(tex, "")
+	md = texSection.ReplaceAllString(md, "\n\n## $1\n\n")
+	md = texFormat.ReplaceAllStringFunc(md, func(s string) string {
+		m := texFormat.FindStringSubmatch(s)
+		switch {
+		case strings.HasPrefix(s, `\textbf`):
+			return "**" + m[1] + "**"
+		case strings.HasPrefix(s, `\texttt`):
+			return "`" + m[1] + "`"
+		default:
+			return "*" + m[1] + "*"
+		}
+	})
+	md = texEnv.ReplaceAllString(md, "")
+	md = texItem.ReplaceAllString(md, "$1- ")
+	md = strings.NewReplacer(`\\`, "\n", `~`, " ", `\%`, "%", `\&`, "&", `\_`, "_").Replace(md)
+	md = texBlankRuns.ReplaceAllString(md, "\n\n")
+	return strings.TrimSpace(md)
+}
+
+// testFiles pairs the inputs in dir with their answer files, sorted by name.
+func testFiles(fsys fs.FS, dir, answerExt string) ([][2]string, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	var pairs [][2]string
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".in" {
+			continue
+		}
+		in := path.Join(dir, entry.Name())
+		ans := strings.TrimSuffix(in, ".in") + answerExt
+		if !exists(fsys, ans) {
+			return nil, fmt.Errorf("test %s has no %s file", in, answerExt)
+		}
+		pairs = append(pairs, [2]string{in, ans})
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i][0] < pairs[j][0] })
+	return pairs, nil
+}
+
+func readTest(fsys fs.FS, id, in, ans string, hidden bool) (models.TestCase, error) {
+	input, err := readText(fsys, in)
+	if err != nil {
+		return models.TestCase{}, err
+	}
+	output, err := readText(fsys, ans)
+	if err != nil {
+		return models.TestCase{}, err
+	}
+	return models.TestCase{ID: id, Input: input, ExpectedOutput: output, Hidden: hidden}, nil
+}
@@ -0,0 +1,403 @@
+package packages
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"strathlearn/backend/models"
+)
+
+const polygonXML = `<?xml version="1.0" encoding="utf-8" standalone="no"?>
+<problem revision="12" short-name="a-plus-b" url="https://polygon.codeforces.com/p/author/a-plus-b">
+    <names>
+        <name language="russian" value="A+B"/>
+        <name language="english" value="A plus B"/>
+    </names>
+    <statements>
+        <statement charset="UTF-8" language="english" mathjax="true" path="statements/english/problem.tex" type="application/x-tex"/>
+    </statements>
+    <judging cpu-name="Intel(R) Core(TM) i3-8100 CPU @ 3.60GHz" cpu-speed="3600" input-file="" output-file="">
+        <testset name="tests">
+            <time-limit>2500</time-limit>
+            <memory-limit>268435456</memory-limit>
+            <test-count>3</test-count>
+            <input-path-pattern>tests/%02d</input-path-pattern>
+            <answer-path-pattern>tests/%02d.a</answer-path-pattern>
+            <tests>
+                <test method="manual" sample="true" group="samples" points="0"/>
+                <test method="manual" group="small" points="30"/>
+                <test method="generated" cmd="gen 100" group="large" points="70"/>
+            </tests>
+            <groups>
+                <group feedback-policy="complete" name="samples" points-policy="each-test"/>
+                <group feedback-policy="points" name="small" points="30" points-policy="complete-group"/>
+                <group feedback-policy="points" name="large" points-policy="complete-group">
+                    <dependencies>
+                        <dependency group="small"/>
+                    </dependencies>
+                </group>
+            </groups>
+        </testset>
+    </judging>
+    <assets>
+        <checker name="std::wcmp.cpp" type="testlib">
+            <source path="files/check.cpp" type="cpp.g++17"/>
+        </checker>
+        <solutions>
+            <solution tag="accepted">
+                <source path="solutions/slow.c" type="c.gcc"/>
+            </solution>
+            <solution tag="main">
+                <source path="solutions/main.c" type="c.gcc"/>
+            </solution>
+            <solution tag="accepted">
+                <source path="solutions/fast.cpp" type="cpp.g++17"/>
+            </solution>
+            <solution tag="wrong-answer">
+                <source path="solutions/wa.c" type="c.gcc"/>
+            </solution>
+        </solutions>
+    </assets>
+</problem>
+`
+
+func polygonPackage() fstest.MapFS {
+	return fstest.MapFS{
+		"problem.xml":                           {Data: []byte(polygonXML)},
+		"statement-sections/english/legend.tex": {Data: []byte(`Given \textbf{two} integers $a$ and $b$, print $a+b$.`)},
+		"statement-sections/english/input.tex":  {Data: []byte(`One line with \texttt{a b}.`)},
+		"statement-sections/english/output.tex": {Data: []byte("")},
+		"tests/01":                              {Data: []byte("1 2\r\n")},
+		"tests/01.a":                            {Data: []byte("3\r\n")},
+		"tests/02":                              {Data: []byte("5 5\n")},
+		"tests/02.a":                            {Data: []byte("10\n")},
+		"tests/03":                              {Data: []byte("1000000 1\n")},
+		"tests/03.a":                            {Data: []byte("1000001\n")},
+		"solutions/main.c":                      {Data: []byte("int main() { return 0; }\n")},
+		"solutions/slow.c":                      {Data: []byte("int main() { for (;;); }\n")},
+		"solutions/fast.cpp":                    {Data: []byte("int main() {}\n")},
+		"solutions/wa.c":                        {Data: []byte("int main() { return 1; }\n")},
+		"files/check.cpp":                       {Data: []byte("// testlib checker\n")},
+		"statements/.html/en":                   {Data: []byte("<p>ignored</p>")},
+	}
+}
+
+func TestImportPolygon(t *testing.T) {
+	result, err := importPolygon(polygonPackage(), "package-dir")
+	if err != nil {
+		t.Fatalf("importPolygon: %v", err)
+	}
+	ch := result.Challenge
+
+	if ch.ID != "a-plus-b" || ch.Title != "A plus B" {
+		t.Errorf("id, title = %q, %q", ch.ID, ch.Title)
+	}
+	if ch.TimeLimit != 3 || ch.MemoryLimit != 256 {
+		t.Errorf("limits = %ds, %dMB, want 3s, 256MB", ch.TimeLimit, ch.MemoryLimit)
+	}
+	wantDescription := "Given **two** integers $a$ and $b$, print $a+b$.\n\n## Input\n\nOne line with `a b`."
+	if ch.Description != wantDescription {
+		t.Errorf("description = %q, want %q", ch.Description, wantDescription)
+	}
+
+	wantTests := []models.TestCase{
+		{ID: "1", Input: "1 2\n", ExpectedOutput: "3\n"},
+		{ID: "2", Input: "5 5\n", ExpectedOutput: "10\n", Hidden: true},
+		{ID: "3", Input: "1000000 1\n", ExpectedOutput: "1000001\n", Hidden: true},
+	}
+	if !reflect.DeepEqual(ch.TestCases, wantTests) {
+		t.Errorf("tests = %+v, want %+v", ch.TestCases, wantTests)
+	}
+
+	wantSubtasks := []models.Subtask{
+		{ID: "samples", Name: "samples", TestCases: []string{"1"}},
+		{ID: "small", Name: "small", Points: 30, TestCases: []string{"2"}},
+		{ID: "large", Name: "large", Points: 70, TestCases: []string{"3"}, DependsOn: []string{"small"}},
+	}
+	if !reflect.DeepEqual(ch.Subtasks, wantSubtasks) {
+		t.Errorf("subtasks = %+v, want %+v", ch.Subtasks, wantSubtasks)
+	}
+
+	wantSolutions := []string{"int main() { return 0; }\n", "int main() { for (;;); }\n"}
+	if !reflect.DeepEqual(ch.Solutions, wantSolutions) {
+		t.Errorf("solutions = %q, want the main solution first and no C++ or wrong answers", ch.Solutions)
+	}
+
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "LaTeX") {
+		t.Errorf("warnings = %q, want only the LaTeX review warning", result.Warnings)
+	}
+}
+
+func TestImportPolygonWarnsAboutUnsupportedFeatures(t *testing.T) {
+	fsys := polygonPackage()
+	xml := strings.Replace(polygonXML, `input-file=""`, `input-file="input.txt"`, 1)
+	xml = strings.Replace(xml, `name="std::wcmp.cpp"`, `name="std::rcmp6.cpp"`, 1)
+	fsys["problem.xml"] = &fstest.MapFile{Data: []byte(xml)}
+
+	result, err := importPolygon(fsys, "a-plus-b")
+	if err != nil {
+		t.Fatalf("importPolygon: %v", err)
+	}
+	joined := strings.Join(result.Warnings, "\n")
+	for _, want := range []string{"named files", "checker std::rcmp6.cpp is not supported"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("warnings = %q, want one mentioning %q", result.Warnings, want)
+		}
+	}
+}
+
+func TestImportPolygonErrors(t *testing.T) {
+	missingGenerated := polygonPackage()
+	delete(missingGenerated, "tests/03")
+
+	missingAnswer := polygonPackage()
+	delete(missingAnswer, "tests/02.a")
+
+	noTestsets := polygonPackage()
+	noTestsets["problem.xml"] = &fstest.MapFile{Data: []byte(`<problem short-name="x"><judging/></problem>`)}
+
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+		want string
+	}{
+		{"not a package", fstest.MapFS{}, "not a Polygon package"},
+		{"bad xml", fstest.MapFS{"problem.xml": {Data: []byte("<problem>")}}, "problem.xml"},
+		{"generated test missing", missingGenerated, "export a full package"},
+		{"answer missing", missingAnswer, "tests/02.a"},
+		{"no testsets", noTestsets, "no testsets"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := importPolygon(tt.fsys, "x")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func kattisPackage() fstest.MapFS {
+	return fstest.MapFS{
+		"problem.yaml":                       {Data: []byte("source: NWERC\nname:\n  en: Hello Kattis\n  sv: Hej Kattis\nlimits:\n  memory: 512\n")},
+		".timelimit":                         {Data: []byte("1.5\n")},
+		"statement/problem.en.md":            {Data: []byte("# Hello\n\nPrint a greeting.\n")},
+		"data/sample/1.in":                   {Data: []byte("")},
+		"data/sample/1.ans":                  {Data: []byte("Hello\n")},
+		"data/secret/group1/testdata.yaml":   {Data: []byte("accept_score: 30\n")},
+		"data/secret/group1/a.in":            {Data: []byte("a\n")},
+		"data/secret/group1/a.ans":           {Data: []byte("Hello a\n")},
+		"data/secret/group2/b.in":            {Data: []byte("b\n")},
+		"data/secret/group2/b.ans":           {Data: []byte("Hello b\n")},
+		"submissions/accepted/hello.c":       {Data: []byte("int main() { return 0; }\n")},
+		"submissions/accepted/hello.py":      {Data: []byte("print('Hello')\n")},
+		"submissions/wrong_answer/nothing.c": {Data: []byte("int main() { return 1; }\n")},
+	}
+}
+
+func TestImportKattis(t *testing.T) {
+	result, err := importKattis(kattisPackage(), "hello")
+	if err != nil {
+		t.Fatalf("importKattis: %v", err)
+	}
+	ch := result.Challenge
+
+	if ch.ID != "hello" || ch.Title != "Hello Kattis" {
+		t.Errorf("id, title = %q, %q", ch.ID, ch.Title)
+	}
+	if ch.TimeLimit != 2 || ch.MemoryLimit != 512 {
+		t.Errorf("limits = %ds, %dMB, want 2s, 512MB", ch.TimeLimit, ch.MemoryLimit)
+	}
+	if ch.Description != "# Hello\n\nPrint a greeting." {
+		t.Errorf("description = %q", ch.Description)
+	}
+
+	wantTests := []models.TestCase{
+		{ID: "sample-1", Input: "", ExpectedOutput: "Hello\n"},
+		{ID: "secret-group1-a", Input: "a\n", ExpectedOutput: "Hello a\n", Hidden: true},
+		{ID: "secret-group2-b", Input: "b\n", ExpectedOutput: "Hello b\n", Hidden: true},
+	}
+	if !reflect.DeepEqual(ch.TestCases, wantTests) {
+		t.Errorf("tests = %+v, want %+v", ch.TestCases, wantTests)
+	}
+
+	wantSubtasks := []models.Subtask{
+		{ID: "secret-group1", Name: "secret/group1", Points: 30, TestCases: []string{"secret-group1-a"}},
+		{ID: "secret-group2", Name: "secret/group2", Points: 1, TestCases: []string{"secret-group2-b"}},
+	}
+	if !reflect.DeepEqual(ch.Subtasks, wantSubtasks) {
+		t.Errorf("subtasks = %+v, want %+v", ch.Subtasks, wantSubtasks)
+	}
+
+	if !reflect.DeepEqual(ch.Solutions, []string{"int main() { return 0; }\n"}) {
+		t.Errorf("solutions = %q, want only the accepted C submission", ch.Solutions)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("warnings = %q, want none", result.Warnings)
+	}
+}
+
+func TestImportKattisWithoutGroups(t *testing.T) {
+	fsys := fstest.MapFS{
+		"problem.yaml":           {Data: []byte("name: Plain\nvalidation: custom\n")},
+		"data/secret/1.in":       {Data: []byte("1\n")},
+		"data/secret/1.ans":      {Data: []byte("1\n")},
+		"data/secret/2.in":       {Data: []byte("2\n")},
+		"data/secret/2.ans":      {Data: []byte("2\n")},
+		"output_validators/v.py": {Data: []byte("")},
+	}
+	result, err := importKattis(fsys, "plain")
+	if err != nil {
+		t.Fatalf("importKattis: %v", err)
+	}
+	if len(result.Challenge.TestCases) != 2 || len(result.Challenge.Subtasks) != 0 {
+		t.Errorf("tests, subtasks = %+v, %+v, want 2 tests scored one point each", result.Challenge.TestCases, result.Challenge.Subtasks)
+	}
+	joined := strings.Join(result.Warnings, "\n")
+	for _, want := range []string{"no time limit", "custom output validator", "no problem statement", "no accepted C submission"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("warnings = %q, want one mentioning %q", result.Warnings, want)
+		}
+	}
+}
+
+func TestImportKattisErrors(t *testing.T) {
+	missingAnswer := kattisPackage()
+	delete(missingAnswer, "data/secret/group2/b.ans")
+
+	if _, err := importKattis(fstest.MapFS{}, "x"); err == nil || !strings.Contains(err.Error(), "not a Kattis package") {
+		t.Errorf("empty package: error = %v", err)
+	}
+	if _, err := importKattis(missingAnswer, "x"); err == nil || !strings.Contains(err.Error(), "has no .ans file") {
+		t.Errorf("missing answer: error = %v", err)
+	}
+}
+
+func exportedChallenges() []models.Challenge {
+	return []models.Challenge{
+		{
+			SchemaVersion: models.ChallengeSchemaVersion,
+			ID:            "sum",
+			Title:         "Sum",
+			Description:   "Print a+b for <a> and <b>.",
+			TimeLimit:     1,
+			MemoryLimit:   64,
+			Solutions:     []string{"#include <stdio.h>\nint main() { return 0; }\n"},
+			TestCases: []models.TestCase{
+				{ID: "sample", Input: "1 2\n", ExpectedOutput: "3\n"},
+				{ID: "big", Input: "1000 2000\n", ExpectedOutput: "3000\n", Hidden: true},
+			},
+			Subtasks: []models.Subtask{{ID: "all", Points: 10, TestCases: []string{"sample", "big"}}},
+		},
+		{
+			SchemaVersion: models.ChallengeSchemaVersion,
+			ID:            "hello",
+			Title:         "Hello",
+			TimeLimit:     2,
+			MemoryLimit:   32,
+			TestCases:     []models.TestCase{{ID: "t1", ExpectedOutput: "Hello\n"}},
+		},
+	}
+}
+
+// exportToFile exports challenges to a zip file and returns its path.
+func exportToFile(t *testing.T, challenges []models.Challenge) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Export(&buf, challenges); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	file := filepath.Join(t.TempDir(), "package.zip")
+	if err := os.WriteFile(file, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	challenges := exportedChallenges()
+	results, err := Import("strathlearn", exportToFile(t, exportedChallenges()))
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("imported %d challenges, want 2", len(results))
+	}
+
+	// Export sorts by ID, so hello comes first.
+	for i, want := range []models.Challenge{challenges[1], challenges[0]} {
+		if got := results[i].Challenge; !reflect.DeepEqual(got, want) {
+			t.Errorf("round trip of %s:\n got %+v\nwant %+v", want.ID, got, want)
+		}
+	}
+}
+
+func TestExportSingleChallengeRoundTrip(t *testing.T) {
+	want := exportedChallenges()[0]
+	results, err := Import("strathlearn", exportToFile(t, []models.Challenge{want}))
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if len(results) != 1 || !reflect.DeepEqual(results[0].Challenge, want) {
+		t.Errorf("round trip = %+v, want %+v", results, want)
+	}
+}
+
+func TestExportInlinesGenerators(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "gen.c"), []byte("int main() { return 0; }\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	challenge := exportedChallenges()[1]
+	challenge.FilePath = filepath.Join(dir, "hello.json")
+	challenge.Generator = &models.Generator{File: "gen.c", Seeds: []string{"1", "2"}}
+
+	results, err := Import("strathlearn", exportToFile(t, []models.Challenge{challenge}))
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	gen := results[0].Challenge.Generator
+	if gen == nil || gen.File != "" || gen.Source != "int main() { return 0; }\n" {
+		t.Errorf("generator = %+v, want the source inlined", gen)
+	}
+}
+
+func TestExportRejectsUnsafeNames(t *testing.T) {
+	bad := exportedChallenges()[0]
+	bad.ID = "../escape"
+	if err := Export(&bytes.Buffer{}, []models.Challenge{bad}); err == nil {
+		t.Error("exported a challenge ID with a path separator")
+	}
+
+	bad = exportedChallenges()[0]
+	bad.TestCases[0].ID = ".."
+	if err := Export(&bytes.Buffer{}, []models.Challenge{bad}); err == nil {
+		t.Error("exported a test case ID of ..")
+	}
+}
+
+func TestImportUnknownFormat(t *testing.T) {
+	if _, err := Import("hackerrank", t.TempDir()); err == nil || !strings.Contains(err.Error(), "unknown package format") {
+		t.Errorf("error = %v, want an unknown format error", err)
+	}
+}
+
+func TestTexToMarkdown(t *testing.T) {
+	tex := `\begin{problem}{Sum}{standard input}{standard output}{1 second}
+\section*{Input}
+Read \emph{two} numbers~$a$ and $b$ \\ 100\% of them.
+\begin{itemize}
+  \item first
+  \item second
+\end{itemize}
+\end{problem}`
+	want := "## Input\n\nRead *two* numbers $a$ and $b$ \n 100% of them.\n\n  - first\n  - second"
+	if got := texToMarkdown(tex); got != want {
+		t.Errorf("texToMarkdown =\n%q\nwant\n%q", got, want)
+	}
+}
@@ -0,0 +1,247 @@
+package packages
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/fs"
+	"math"
+	"path"
+	"strings"
+
+	"strathlearn/backend/models"
+)
+
+type polygonProblem struct {
+	ShortName string `xml:"short-name,attr"`
+	Names     []struct {
+		Language string `xml:"language,attr"`
+		Value    string `xml:"value,attr"`
+	} `xml:"names>name"`
+	Judging struct {
+		InputFile  string           `xml:"input-file,attr"`
+		OutputFile string           `xml:"output-file,attr"`
+		Testsets   []polygonTestset `xml:"testset"`
+	} `xml:"judging"`
+	Checker struct {
+		Name   string `xml:"name,attr"`
+		Source struct {
+			Path string `xml:"path,attr"`
+		} `xml:"source"`
+	} `xml:"assets>checker"`
+	Solutions []struct {
+		Tag    string `xml:"tag,attr"`
+		Source struct {
+			Path string `xml:"path,attr"`
+			Type string `xml:"type,attr"`
+		} `xml:"source"`
+	} `xml:"assets>solutions>solution"`
+	Statements []struct {
+		Language string `xml:"language,attr"`
+		Path     string `xml:"path,attr"`
+		Type     string `xml:"type,attr"`
+	} `xml:"statements>statement"`
+}
+
+type polygonTestset struct {
+	Name          string `xml:"name,attr"`
+	TimeLimit     int    `xml:"time-limit"`
+	MemoryLimit   int64  `xml:"memory-limit"`
+	InputPattern  string `xml:"input-path-pattern"`
+	AnswerPattern string `xml:"answer-path-pattern"`
+	Tests         []struct {
+		Method string  `xml:"method,attr"`
+		Sample bool    `xml:"sample,attr"`
+		Points float64 `xml:"points,attr"`
+		Group  string  `xml:"group,attr"`
+	} `xml:"tests>test"`
+	Groups []struct {
+		Name         string  `xml:"name,attr"`
+		Points       float64 `xml:"points,attr"`
+		Dependencies []struct {
+			Group string `xml:"group,attr"`
+		} `xml:"dependencies>dependency"`
+	} `xml:"groups>group"`
+}
+
+// polygonExactCheckers are the testlib standard checkers whose behaviour
+// matches our whitespace-insensitive output comparison.
+var polygonExactCheckers = map[string]bool{
+	"std::wcmp.cpp": true,
+	"std::lcmp.cpp": true,
+	"std::ncmp.cpp": true,
+	"std::icmp.cpp": true,
+	"std::hcmp.cpp": true,
+	"std::fcmp.cpp": true,
+}
+
+// importPolygon converts a Polygon package. Generated tests must be present
+// in the package, so export a full package from Polygon rather than a
+// standard one.
+func importPolygon(fsys fs.FS, name string) (Result, error) {
+	result := Result{Challenge: models.Challenge{
+		SchemaVersion: models.ChallengeSchemaVersion,
+		ID:            name,
+		Title:         name,
+		TimeLimit:     1,
+		MemoryLimit:   defaultMemoryLimit,
+		Hints:         []string{},
+	}}
+	ch := &result.Challenge
+
+	raw, err := fs.ReadFile(fsys, "problem.xml")
+	if err != nil {
+		return result, fmt.Errorf("not a Polygon package: %w", err)
+	}
+	var problem polygonProblem
+	if err := xml.Unmarshal(raw, &problem); err != nil {
+		return result, fmt.Errorf("problem.xml: %w", err)
+	}
+
+	if problem.ShortName != "" {
+		ch.ID = problem.ShortName
+		ch.Title = problem.ShortName
+	}
+	for i, n := range problem.Names {
+		if i == 0 || n.Language == "english" {
+			ch.Title = n.Value
+		}
+	}
+
+	if problem.Judging.InputFile != "" || problem.Judging.OutputFile != "" {
+		result.warn("problem reads or writes named files; submissions will use standard input and output")
+	}
+	if checker := problem.Checker.Name; checker != "" && !polygonExactCheckers[checker] {
+		result.warn("checker %s is not supported; output will be compared exactly", checker)
+	} else if checker == "" && problem.Checker.Source.Path != "" {
+		result.warn("custom checker %s is not supported; output will be compared exactly", problem.Checker.Source.Path)
+	}
+
+	ch.Description = polygonStatement(fsys, problem, &result)
+
+	if len(problem.Judging.Testsets) == 0 {
+		return result, fmt.Errorf("problem.xml has no testsets")
+	}
+	testset := problem.Judging.Testsets[0]
+	for _, ts := range problem.Judging.Testsets {
+		if ts.Name == "tests" {
+			testset = ts
+		}
+	}
+	if testset.TimeLimit > 0 {
+		ch.TimeLimit = int(math.Ceil(float64(testset.TimeLimit) / 1000))
+	}
+	if testset.MemoryLimit > 0 {
+		ch.MemoryLimit = int(testset.MemoryLimit / (1024 * 1024))
+	}
+
+	groupTests := make(map[string][]string)
+	var pointTests []models.Subtask
+	for i, test := range testset.Tests {
+		id := fmt.Sprintf("%d", i+1)
+		in := fmt.Sprintf(testset.InputPattern, i+1)
+		ans := fmt.Sprintf(testset.AnswerPattern, i+1)
+		if !exists(fsys, in) {
+			if test.Method == "generated" {
+				return result, fmt.Errorf("test %d is generated and missing from the package; export a full package", i+1)
+			}
+			return result, fmt.Errorf("test %d: %s not found", i+1, in)
+		}
+		tc, err := readTest(fsys, id, in, ans, !test.Sample)
+		if err != nil {
+			return result, err
+		}
+		ch.TestCases = append(ch.TestCases, tc)
+
+		if test.Group != "" {
+			groupTests[test.Group] = append(groupTests[test.Group], id)
+		} else if test.Points > 0 {
+			pointTests = append(pointTests, models.Subtask{
+				ID:        id,
+				Points:    int(math.Round(test.Points)),
+				TestCases: []string{id},
+			})
+		}
+	}
+
+	for _, group := range testset.Groups {
+		subtask := models.Subtask{
+			ID:        group.Name,
+			Name:      group.Name,
+			Points:    int(math.Round(group.Points)),
+			TestCases: groupTests[group.Name],
+		}
+		if subtask.Points == 0 {
+			for _, test := range testset.Tests {
+				if test.Group == group.Name {
+					subtask.Points += int(math.Round(test.Points))
+				}
+			}
+		}
+		for _, dep := range group.Dependencies {
+			subtask.DependsOn = append(subtask.DependsOn, dep.Group)
+		}
+		ch.Subtasks = append(ch.Subtasks, subtask)
+	}
+	ch.Subtasks = append(ch.Subtasks, pointTests...)
+
+	for _, solution := range problem.Solutions {
+		if solution.Tag != "main" && solution.Tag != "accepted" {
+			continue
+		}
+		if !isCSource(solution.Source.Path) && !strings.HasPrefix(solution.Source.Type, "c.") {
+			continue
+		}
+		source, err := readText(fsys, solution.Source.Path)
+		if err != nil {
+			return result, err
+		}
+		if solution.Tag == "main" {
+			ch.Solutions = append([]string{source}, ch.Solutions...)
+		} else {
+			ch.Solutions = append(ch.Solutions, source)
+		}
+	}
+	if len(ch.Solutions) == 0 {
+		result.warn("no main or accepted C solution to use as a reference solution")
+	}
+
+	return result, nil
+}
+
+// polygonStatement builds the description from the English statement
+// sections, falling back to the full LaTeX statement.
+func polygonStatement(fsys fs.FS, problem polygonProblem, result *Result) string {
+	sections := []struct{ file, heading string }{
+		{"legend.tex", ""},
+		{"input.tex", "## Input"},
+		{"output.tex", "## Output"},
+		{"notes.tex", "## Notes"},
+	}
+	var parts []string
+	for _, section := range sections {
+		text, err := readText(fsys, path.Join("statement-sections/english", section.file))
+		if err != nil || strings.TrimSpace(text) == "" {
+			continue
+		}
+		if section.heading != "" {
+			parts = append(parts, section.heading)
+		}
+		parts = append(parts, texToMarkdown(text))
+	}
+	if len(parts) > 0 {
+		result.warn("statement converted from LaTeX; review the description")
+		return strings.Join(parts, "\n\n")
+	}
+
+	for _, statement := range problem.Statements {
+		if statement.Language != "english" || statement.Type != "application/x-tex" {
+			continue
+		}
+		if text, err := readText(fsys, statement.Path); err == nil {
+			result.warn("statement converted from LaTeX; review the description")
+			return texToMarkdown(text)
+		}
+	}
+	result.warn("no English statement found")
+	return ""
+}
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"strathlearn/backend/db"
+)
+
+// ProfileRepository stores per-user profile data.
+type ProfileRepository interface {
+	Get(userID string) (db.Profile, bool, error)
+	SetLocale(userID, locale string) error
+	// RecordSubmission updates the author's streak and submission totals
+	// and, for their first accepted submission on a challenge, solve counts.
+	// It reports whether the submission was that first solve.
+	RecordSubmission(submission db.Submission, difficulty string) (bool, error)
+	// Rebuild recomputes a user's stats from all their submissions, for when
+	// verdicts changed after the submissions were recorded.
+	Rebuild(userID string, difficulty func(challengeID string) string) error
+	SolvedByDifficulty(userID string) (map[string]int, error)
+}
+
+// DBProfileStore keeps profiles in the database.
+type DBProfileStore struct {
+	db *gorm.DB
+}
+
+func NewDBProfileStore(conn *gorm.DB) *DBProfileStore {
+	return &DBProfileStore{db: conn}
+}
+
+func (s *DBProfileStore) Get(userID string) (db.Profile, bool, error) {
+	var profile db.Profile
+	result := s.db.Where("user_id = ?", userID).Limit(1).Find(&profile)
+	return profile, result.RowsAffected > 0, result.Error
+}
+
+// SetLocale saves the user's preferred locale, creating their profile if
+// they have none yet.
+func (s *DBProfileStore) SetLocale(userID, locale string) error {
+	var profile db.Profile
+	return s.db.Where(db.Profile{UserId: userID}).
+		Assign(map[string]interface{}{"locale": locale}).
+		FirstOrCreate(&profile).Error
+}
+
+func (s *DBProfileStore) RecordSubmission(submission db.Submission, difficulty string) (bool, error) {
+	var firstSolve bool
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if firstSolve, err = recordSubmission(tx, submission, difficulty); err != nil {
+			return err
+		}
+		return refreshTotals(tx, submission.UserID)
+	})
+	return firstSolve, err
+}
+
+// Rebuild replays the user's submissions oldest first. Difficulties are
+// looked up before the transaction starts, since the lookup may need a
+// connection of its own.
+func (s *DBProfileStore) Rebuild(userID string, difficulty func(challengeID string) string) error {
+	var challengeIDs []string
+	err := s.db.Model(&db.Submission{}).Where("user_id = ?", userID).
+		Distinct("challenge_id").Pluck("challenge_id", &challengeIDs).Error
+	if err != nil {
+		return err
+	}
+	difficulties := make(map[string]string, len(challengeIDs))
+	for _, id := range challengeIDs {
+		difficulties[id] = difficulty(id)
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&db.Solve{}).Error; err != nil {
+			return err
+		}
+		err := tx.Model(&db.Profile{}).Where("user_id = ?", userID).UpdateColumns(map[string]interface{}{
+			"challenges_solved": 0,
+			"streaks":           0,
+			"longest_streak":    0,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		var submissions []db.Submission
+		err = tx.Select("id, user_id, challenge_id, verdict, status_code, created_at").
+			Where("user_id = ?", userID).
+			Order("created_at ASC, id ASC").
+			Find(&submissions).Error
+		if err != nil {
+			return err
+		}
+		for _, submission := range submissions {
+			d, ok := difficulties[submission.ChallengeID]
+			if !ok {
+				d = UnratedDifficulty
+			}
+			if _, err := recordSubmission(tx, submission, d); err != nil {
+				return err
+			}
+		}
+		return refreshTotals(tx, userID)
+	})
+}
+
+func (s *DBProfileStore) SolvedByDifficulty(userID string) (map[string]int, error) {
+	var counts []struct {
+		Difficulty string
+		Count      int
+	}
+	err := s.db.Model(&db.Solve{}).
+		Select("difficulty, COUNT(*) as count").
+		Where("user_id = ?", userID).
+		Group("difficulty").
+		Find(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	solved := make(map[string]int, len(counts))
+	for _, c := range counts {
+		solved[c.Difficulty] = c.Count
+	}
+	return solved, nil
+}
+
+// recordSubmission applies one submission to its author's profile inside
+// tx. The profile row is locked so concurrent submissions by the same user
+// cannot lose each other's updates.
+func recordSubmission(tx *gorm.DB, submission db.Submission, difficulty string) (bool, error) {
+	err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&db.Profile{UserId: submission.UserID}).Error
+	if err != nil {
+		return false, err
+	}
+	var profile db.Profile
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ?", submission.UserID).
+		First(&profile).Error
+	if err != nil {
+		return false, err
+	}
+
+	advanceStreak(&profile, submission.CreatedAt)
+
+	firstSolve := false
+	if isAccepted(submission) {
+		gaveUp, err := hasGivenUp(tx, submission.UserID, submission.ChallengeID)
+		if err != nil {
+			return false, err
+		}
+		if !gaveUp {
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&db.Solve{
+				UserID:       submission.UserID,
+				ChallengeID:  submission.ChallengeID,
+				Difficulty:   difficulty,
+				SubmissionID: submission.ID,
+				SolvedAt:     submission.CreatedAt,
+			})
+			if result.Error != nil {
+				return false, result.Error
+			}
+			firstSolve = result.RowsAffected > 0
+		}
+	}
+	if firstSolve {
+		profile.ChallengesSolved++
+	}
+
+	err = tx.Model(&profile).
+		Select("challenges_solved", "streaks", "longest_streak", "last_streak_date").
+		Updates(&profile).Error
+	return firstSolve, err
+}
+
+// refreshTotals recounts the submission totals on a user's profile from
+// their submissions. Recounting rather than adding keeps them right after a
+// rejudge or give-up changes scores.
+func refreshTotals(tx *gorm.DB, userID string) error {
+	var count int64
+	if err := tx.Model(&db.Submission{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return err
+	}
+	var first, last db.Submission
+	err := tx.Select("created_at").Where("user_id = ?", userID).Order("created_at ASC").Limit(1).Find(&first).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Select("created_at").Where("user_id = ?", userID).Order("created_at DESC").Limit(1).Find(&last).Error
+	if err != nil {
+		return err
+	}
+	var scores struct {
+		TotalScore          int
+		ChallengesCompleted int
+	}
+	err = tx.Table("(?) AS best", bestScores(tx).Where("user_id = ?", userID)).
+		Select("COALESCE(SUM(best_score), 0) AS total_score, " +
+			"COUNT(CASE WHEN max_score > 0 AND raw_score >= max_score THEN 1 END) AS challenges_completed").
+		Scan(&scores).Error
+	if err != nil {
+		return err
+	}
+
+	totals := map[string]interface{}{
+		"total_submissions":    count,
+		"total_score":          scores.TotalScore,
+		"challenges_completed": scores.ChallengesCompleted,
+		"first_submission_at":  nil,
+		"last_submission_at":   nil,
+	}
+	if count > 0 {
+		totals["first_submission_at"] = first.CreatedAt
+		totals["last_submission_at"] = last.CreatedAt
+	}
+	return tx.Model(&db.Profile{}).Where("user_id = ?", userID).UpdateColumns(totals).Error
+}
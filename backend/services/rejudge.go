@@ -0,0 +1,129 @@
+package services
+
+import (
+	"log"
+	"sort"
+	"sync"
+
+	"strathlearn/backend/db"
+	"strathlearn/backend/models"
+	"strathlearn/backend/services/rules"
+	"strathlearn/backend/services/runner"
+	"strathlearn/backend/services/scoring"
+)
+
+// VerdictChange describes a submission whose outcome changed on rejudge.
+type VerdictChange struct {
+	SubmissionID string `json:"submissionId"`
+	UserID       string `json:"userId"`
+	OldVersion   int    `json:"oldVersion"`
+	OldVerdict   string `json:"oldVerdict"`
+	NewVerdict   string `json:"newVerdict"`
+	OldScore     int    `json:"oldScore"`
+	NewScore     int    `json:"newScore"`
+}
+
+type RejudgeReport struct {
+	ChallengeID string          `json:"challengeId"`
+	Version     int             `json:"version"`
+	Total       int             `json:"total"`
+	Unchanged   int             `json:"unchanged"`
+	Failed      int             `json:"failed"`
+	Changes     []VerdictChange `json:"changes"`
+}
+
+// Rejudge re-runs the stored submissions of a challenge against its latest
+// version and updates their verdicts and scores. With onlyAccepted set, only
+// submissions that are currently accepted are rejudged, which is the cheap
+// way to catch solutions that passed a broken test. At most workers
+// submissions are in r at once, and progress is called after each one.
+func Rejudge(submissions SubmissionRepository, r runner.CodeRunner, versions VersionRepository, challenge models.Challenge, onlyAccepted bool, workers int, progress func(done, total int)) (RejudgeReport, error) {
+	report := RejudgeReport{ChallengeID: challenge.ID}
+
+	version, err := versions.Record(challenge)
+	if err != nil {
+		return report, err
+	}
+	report.Version = version
+
+	rows, err := submissions.ForChallenge(challenge.ID, onlyAccepted)
+	if err != nil {
+		return report, err
+	}
+	report.Total = len(rows)
+	progress(0, report.Total)
+
+	pending := make(chan db.Submission)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	done := 0
+	for i := 0; i < max(workers, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for submission := range pending {
+				results := judgeCode(r, submission.Code, challenge)
+				raw, maxScore, _ := scoring.Score(challenge, results)
+				score := scoring.ApplyHintPenalty(raw, submission.HintsUsed, hintPenalty(challenge))
+
+				updated := submission
+				ApplyResults(&updated, results)
+				updated.Score = score
+				updated.HintPenalty = raw - score
+				updated.MaxScore = maxScore
+				updated.ChallengeVersion = version
+				verdict := updated.Verdict
+
+				err := submissions.UpdateOutcome(updated)
+
+				mu.Lock()
+				switch {
+				case err != nil:
+					log.Printf("Failed to save rejudged submission %s: %v", submission.ID, err)
+					report.Failed++
+				case verdict != submission.Verdict || score != submission.Score:
+					report.Changes = append(report.Changes, VerdictChange{
+						SubmissionID: submission.ID,
+						UserID:       submission.UserID,
+						OldVersion:   submission.ChallengeVersion,
+						OldVerdict:   submission.Verdict,
+						NewVerdict:   verdict,
+						OldScore:     submission.Score,
+						NewScore:     score,
+					})
+				default:
+					report.Unchanged++
+				}
+				done++
+				progress(done, report.Total)
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, submission := range rows {
+		pending <- submission
+	}
+	close(pending)
+	wg.Wait()
+
+	sort.Slice(report.Changes, func(i, j int) bool {
+		return report.Changes[i].SubmissionID < report.Changes[j].SubmissionID
+	})
+	log.Printf("Rejudged %d submissions of %s against version %d: %d changed, %d failed",
+		report.Total, challenge.ID, version, len(report.Changes), report.Failed)
+	return report, nil
+}
+
+// judgeCode runs code the way SubmitSolution does, rejecting rule
+// violations before anything is executed.
+func judgeCode(r runner.CodeRunner, code string, challenge models.Challenge) []models.TestResult {
+	if violations := rules.Check(code, challenge.Rules); len(violations) > 0 {
+		return []models.TestResult{{
+			TestCaseID: "rules",
+			Passed:     false,
+			Verdict:    models.VerdictRuleViolation,
+			Error:      rules.Explain(violations),
+		}}
+	}
+	return r.RunTests(code, challenge)
+}
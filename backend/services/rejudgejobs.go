@@ -0,0 +1,187 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"strathlearn/backend/models"
+	"strathlearn/backend/services/runner"
+)
+
+const (
+	RejudgeQueued  = "queued"
+	RejudgeRunning = "running"
+	RejudgeDone    = "done"
+	RejudgeFailed  = "failed"
+)
+
+// maxPendingRejudges bounds how many rejudges can wait behind the running
+// one.
+const maxPendingRejudges = 16
+
+// Finished jobs are kept for their status endpoint until they are older
+// than rejudgeJobTTL or more than maxFinishedRejudges newer ones finished.
+const (
+	rejudgeJobTTL       = 24 * time.Hour
+	maxFinishedRejudges = 100
+)
+
+var ErrRejudgeBacklog = errors.New("too many rejudges are already waiting")
+
+// RejudgeJob is the progress and outcome of one background rejudge.
+type RejudgeJob struct {
+	ID           string         `json:"id"`
+	ChallengeID  string         `json:"challengeId"`
+	OnlyAccepted bool           `json:"onlyAccepted"`
+	Status       string         `json:"status"`
+	Done         int            `json:"done"`
+	Total        int            `json:"total"`
+	Error        string         `json:"error,omitempty"`
+	Report       *RejudgeReport `json:"report,omitempty"`
+	QueuedAt     time.Time      `json:"queuedAt"`
+	FinishedAt   *time.Time     `json:"finishedAt,omitempty"`
+
+	challenge models.Challenge
+}
+
+// RejudgeJobs runs rejudges in the background, one challenge at a time and
+// with only a few submissions in the runner at once, so live submissions
+// sharing the runner queue are not starved. Jobs are kept in memory and
+// forgotten on restart.
+type RejudgeJobs struct {
+	submissions SubmissionRepository
+	runner      runner.CodeRunner
+	versions    VersionRepository
+	stats       *ProfileStats
+	workers     int
+
+	mu      sync.Mutex
+	jobs    map[string]*RejudgeJob
+	pending chan *RejudgeJob
+}
+
+func NewRejudgeJobs(submissions SubmissionRepository, r runner.CodeRunner, versions VersionRepository, stats *ProfileStats, workers int) *RejudgeJobs {
+	j := &RejudgeJobs{
+		submissions: submissions,
+		runner:      r,
+		versions:    versions,
+		stats:       stats,
+		workers:     workers,
+		jobs:        make(map[string]*RejudgeJob),
+		pending:     make(chan *RejudgeJob, maxPendingRejudges),
+	}
+	go j.work()
+	return j
+}
+
+// Start queues a rejudge of challenge and returns the new job.
+func (j *RejudgeJobs) Start(challenge models.Challenge, onlyAccepted bool) (RejudgeJob, error) {
+	job := &RejudgeJob{
+		ID:           uuid.NewString(),
+		ChallengeID:  challenge.ID,
+		OnlyAccepted: onlyAccepted,
+		Status:       RejudgeQueued,
+		QueuedAt:     time.Now(),
+		challenge:    challenge,
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	select {
+	case j.pending <- job:
+	default:
+		return RejudgeJob{}, ErrRejudgeBacklog
+	}
+	j.jobs[job.ID] = job
+	j.prune(job.QueuedAt)
+	return *job, nil
+}
+
+// prune forgets finished jobs past their TTL and all but the newest
+// maxFinishedRejudges. The caller holds j.mu.
+func (j *RejudgeJobs) prune(now time.Time) {
+	var finished []*RejudgeJob
+	for id, job := range j.jobs {
+		if job.FinishedAt == nil {
+			continue
+		}
+		if now.Sub(*job.FinishedAt) > rejudgeJobTTL {
+			delete(j.jobs, id)
+			continue
+		}
+		finished = append(finished, job)
+	}
+	if len(finished) <= maxFinishedRejudges {
+		return
+	}
+	sort.Slice(finished, func(a, b int) bool {
+		return finished[a].FinishedAt.After(*finished[b].FinishedAt)
+	})
+	for _, job := range finished[maxFinishedRejudges:] {
+		delete(j.jobs, job.ID)
+	}
+}
+
+// Get returns a snapshot of a job.
+func (j *RejudgeJobs) Get(id string) (RejudgeJob, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	job, ok := j.jobs[id]
+	if !ok {
+		return RejudgeJob{}, false
+	}
+	return *job, true
+}
+
+func (j *RejudgeJobs) work() {
+	for job := range j.pending {
+		j.update(job, func() { job.Status = RejudgeRunning })
+
+		report, err := Rejudge(j.submissions, j.runner, j.versions, job.challenge, job.OnlyAccepted, j.workers,
+			func(done, total int) {
+				j.update(job, func() { job.Done, job.Total = done, total })
+			})
+		if err == nil {
+			j.rebuildStats(report)
+		}
+
+		j.update(job, func() {
+			now := time.Now()
+			job.FinishedAt = &now
+			if err != nil {
+				log.Printf("Rejudge %s of %s failed: %v", job.ID, job.ChallengeID, err)
+				job.Status = RejudgeFailed
+				job.Error = err.Error()
+				return
+			}
+			job.Status = RejudgeDone
+			job.Report = &report
+		})
+	}
+}
+
+// rebuildStats recomputes the profile stats of every user whose verdict or
+// score changed in a rejudge.
+func (j *RejudgeJobs) rebuildStats(report RejudgeReport) {
+	rebuilt := make(map[string]bool)
+	for _, change := range report.Changes {
+		if rebuilt[change.UserID] {
+			continue
+		}
+		rebuilt[change.UserID] = true
+		if err := j.stats.Rebuild(change.UserID); err != nil {
+			log.Printf("Failed to rebuild stats of %s after rejudging %s: %v", change.UserID, report.ChallengeID, err)
+		}
+	}
+}
+
+func (j *RejudgeJobs) update(job *RejudgeJob, change func()) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	change()
+}
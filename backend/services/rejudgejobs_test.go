@@ -0,0 +1,53 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRejudgeJobsPrune(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	finishedAgo := func(d time.Duration) *time.Time {
+		at := now.Add(-d)
+		return &at
+	}
+
+	j := &RejudgeJobs{jobs: map[string]*RejudgeJob{
+		"running": {ID: "running", Status: RejudgeRunning, QueuedAt: now.Add(-48 * time.Hour)},
+		"queued":  {ID: "queued", Status: RejudgeQueued, QueuedAt: now},
+		"stale":   {ID: "stale", Status: RejudgeDone, FinishedAt: finishedAgo(rejudgeJobTTL + time.Minute)},
+		"recent":  {ID: "recent", Status: RejudgeFailed, FinishedAt: finishedAgo(time.Hour)},
+	}}
+	j.prune(now)
+
+	for _, id := range []string{"running", "queued", "recent"} {
+		if _, ok := j.jobs[id]; !ok {
+			t.Errorf("job %s was pruned", id)
+		}
+	}
+	if _, ok := j.jobs["stale"]; ok {
+		t.Error("job finished past the TTL was kept")
+	}
+}
+
+func TestRejudgeJobsPruneKeepsTheNewestFinished(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	j := &RejudgeJobs{jobs: make(map[string]*RejudgeJob)}
+	for i := 0; i < maxFinishedRejudges+5; i++ {
+		at := now.Add(-time.Duration(i) * time.Minute)
+		id := fmt.Sprintf("job-%03d", i)
+		j.jobs[id] = &RejudgeJob{ID: id, Status: RejudgeDone, FinishedAt: &at}
+	}
+	j.prune(now)
+
+	if len(j.jobs) != maxFinishedRejudges {
+		t.Fatalf("%d jobs kept, want %d", len(j.jobs), maxFinishedRejudges)
+	}
+	for i := 0; i < maxFinishedRejudges+5; i++ {
+		_, kept := j.jobs[fmt.Sprintf("job-%03d", i)]
+		if want := i < maxFinishedRejudges; kept != want {
+			t.Errorf("job-%03d kept = %v, want %v", i, kept, want)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"strathlearn/backend/models"
+)
+
+// ChallengeSet is the in-memory challenge collection shared by the HTTP
+// handlers. Readers get copies, so a submission that is already running keeps
+// the version of the challenge it started with when the set is swapped.
+type ChallengeSet struct {
+	mu         sync.RWMutex
+	challenges map[string]models.Challenge
+}
+
+func NewChallengeSet(challenges map[string]models.Challenge) *ChallengeSet {
+	return &ChallengeSet{challenges: challenges}
+}
+
+func (s *ChallengeSet) Get(id string) (models.Challenge, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	challenge, ok := s.challenges[id]
+	return challenge, ok
+}
+
+// All returns a snapshot of every challenge keyed by ID.
+func (s *ChallengeSet) All() map[string]models.Challenge {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	all := make(map[string]models.Challenge, len(s.challenges))
+	for id, challenge := range s.challenges {
+		all[id] = challenge
+	}
+	return all
+}
+
+func (s *ChallengeSet) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.challenges)
+}
+
+// Replace swaps in a new collection and reports what changed.
+func (s *ChallengeSet) Replace(challenges map[string]models.Challenge) (added, removed, changed []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, challenge := range challenges {
+		old, ok := s.challenges[id]
+		switch {
+		case !ok:
+			added = append(added, id)
+		case !reflect.DeepEqual(old, challenge):
+			changed = append(changed, id)
+		}
+	}
+	for id := range s.challenges {
+		if _, ok := challenges[id]; !ok {
+			removed = append(removed, id)
+		}
+	}
+	s.challenges = challenges
+
+	sort.Strings(added)
+	sort.Strings(removed)
+	sort.Strings(changed)
+	return added, removed, changed
+}
+
+type ReloadReport struct {
+	Applied    bool        `json:"applied"`
+	Total      int         `json:"total"`
+	Added      []string    `json:"added"`
+	Removed    []string    `json:"removed"`
+	Changed    []string    `json:"changed"`
+	Kept       []string    `json:"kept,omitempty"`
+	Errors     []LintIssue `json:"errors,omitempty"`
+	Message    string      `json:"message,omitempty"`
+	ReloadedAt time.Time   `json:"reloadedAt"`
+}
+
+// ChallengeReloader revalidates the challenge roots and swaps the results
+// into a ChallengeSet.
+type ChallengeReloader struct {
+	set      *ChallengeSet
+	roots    []string
+	strict   bool
+	mu       sync.Mutex
+	onReload []func(ReloadReport)
+}
+
+func NewChallengeReloader(set *ChallengeSet, roots []string, strict bool) *ChallengeReloader {
+	return &ChallengeReloader{set: set, roots: roots, strict: strict}
+}
+
+// OnReload registers a function called after each reload that changed the
+// challenge set.
+func (r *ChallengeReloader) OnReload(fn func(ReloadReport)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onReload = append(r.onReload, fn)
+}
+
+// Reload re-reads every root. In strict mode any lint error leaves the
+// current set untouched; otherwise challenges whose files fail to load keep
+// their previous version instead of disappearing.
+func (r *ChallengeReloader) Reload() ReloadReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := ReloadReport{ReloadedAt: time.Now()}
+
+	lint := LintChallenges(r.roots...)
+	for _, issue := range lint.Issues {
+		if issue.Severity == SeverityError {
+			report.Errors = append(report.Errors, issue)
+		}
+	}
+	if r.strict && len(report.Errors) > 0 {
+		report.Total = r.set.Len()
+		report.Message = fmt.Sprintf("Reload rejected: %d lint errors", len(report.Errors))
+		return report
+	}
+
+	loaded, failed := loadChallengeRoots(r.roots)
+	for id, old := range r.set.All() {
+		if _, ok := loaded[id]; ok {
+			continue
+		}
+		if _, broken := failed[old.FilePath]; broken {
+			loaded[id] = old
+			report.Kept = append(report.Kept, id)
+		}
+	}
+	sort.Strings(report.Kept)
+
+	if len(loaded) == 0 {
+		report.Total = r.set.Len()
+		report.Message = "Reload rejected: no challenges could be loaded"
+		return report
+	}
+
+	report.Added, report.Removed, report.Changed = r.set.Replace(loaded)
+	report.Applied = true
+	report.Total = len(loaded)
+	for _, fn := range r.onReload {
+		fn(report)
+	}
+	return report
+}
+
+// Watch reloads the challenge set whenever files under the roots change.
+// Bursts of events, such as an editor saving several files, are coalesced.
+func (r *ChallengeReloader) Watch(debounce time.Duration) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	for _, root := range r.roots {
+		if _, err := os.Stat(root); os.IsNotExist(err) {
+			continue
+		}
+		if err := addDirs(watcher, root); err != nil {
+			watcher.Close()
+			return err
+		}
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		var timer <-chan time.Time
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Has(fsnotify.Create) {
+					if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+						addDirs(watcher, event.Name)
+					}
+				}
+				timer = time.After(debounce)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("Challenge watcher error: %v", err)
+			case <-timer:
+				timer = nil
+				report := r.Reload()
+				logReloadReport(report)
+			}
+		}
+	}()
+
+	return nil
+}
+
+func addDirs(watcher *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return watcher.Add(path)
+		}
+		return nil
+	})
+}
+
+func logReloadReport(report ReloadReport) {
+	if !report.Applied {
+		log.Printf("Challenge reload skipped: %s", report.Message)
+		for _, issue := range report.Errors {
+			log.Printf("Challenge lint %s", issue)
+		}
+		return
+	}
+	log.Printf("Reloaded %d challenges: added %v, removed %v, changed %v, kept %v",
+		report.Total, report.Added, report.Removed, report.Changed, report.Kept)
+}
@@ -0,0 +1,343 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"strathlearn/backend/models"
+)
+
+// Constructs that challenges can list under requiredConstructs.
+const (
+	ConstructWhile     = "while"
+	ConstructDoWhile   = "do-while"
+	ConstructFor       = "for"
+	ConstructIf        = "if"
+	ConstructSwitch    = "switch"
+	ConstructStruct    = "struct"
+	ConstructFunction  = "function"
+	ConstructRecursion = "recursion"
+)
+
+var constructDescriptions = map[string]string{
+	ConstructWhile:     "a while loop",
+	ConstructDoWhile:   "a do-while loop",
+	ConstructFor:       "a for loop",
+	ConstructIf:        "an if statement",
+	ConstructSwitch:    "a switch statement",
+	ConstructStruct:    "a struct definition",
+	ConstructFunction:  "a function other than main",
+	ConstructRecursion: "a recursive function",
+}
+
+// Violation describes a single rule the submitted code broke.
+type Violation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+	Line    int    `json:"line,omitempty"`
+}
+
+// IsKnownConstruct reports whether name can be used in requiredConstructs.
+func IsKnownConstruct(name string) bool {
+	_, ok := constructDescriptions[name]
+	return ok
+}
+
+// Check inspects code against the challenge rules without compiling it.
+func Check(code string, rules *models.CodeRules) []Violation {
+	if rules == nil {
+		return nil
+	}
+
+	tokens := tokenize(code)
+	var violations []Violation
+
+	banned := make(map[string]bool, len(rules.BannedIdentifiers))
+	for _, id := range rules.BannedIdentifiers {
+		banned[id] = true
+	}
+	bannedHeaders := make(map[string]bool, len(rules.BannedHeaders))
+	for _, h := range rules.BannedHeaders {
+		bannedHeaders[h] = true
+	}
+
+	reported := make(map[string]bool)
+	for _, tok := range tokens {
+		switch {
+		case tok.kind == tokPunct && tok.text == "##" && len(banned) > 0 && !reported[tok.text]:
+			// Pasting tokens in a macro can assemble a banned identifier
+			// that never appears in the source.
+			reported[tok.text] = true
+			violations = append(violations, Violation{
+				Rule:    "tokenPasting",
+				Message: "Token pasting with ## is not allowed in this challenge",
+				Line:    tok.line,
+			})
+		case tok.kind == tokIdent && banned[tok.text] && !reported[tok.text]:
+			reported[tok.text] = true
+			violations = append(violations, Violation{
+				Rule:    "bannedIdentifier",
+				Message: fmt.Sprintf("'%s' is not allowed in this challenge", tok.text),
+				Line:    tok.line,
+			})
+		case tok.kind == tokInclude && bannedHeaders[tok.text]:
+			violations = append(violations, Violation{
+				Rule:    "bannedHeader",
+				Message: fmt.Sprintf("Including <%s> is not allowed in this challenge", tok.text),
+				Line:    tok.line,
+			})
+		case tok.kind == tokMacroInclude && len(bannedHeaders) > 0:
+			// A macro can expand to any header, banned ones included.
+			violations = append(violations, Violation{
+				Rule:    "bannedHeader",
+				Message: fmt.Sprintf("Including a header through the macro %s is not allowed in this challenge", tok.text),
+				Line:    tok.line,
+			})
+		}
+	}
+
+	if len(rules.RequiredConstructs) > 0 {
+		found := analyze(tokens)
+		for _, construct := range rules.RequiredConstructs {
+			if found[construct] {
+				continue
+			}
+			desc, ok := constructDescriptions[construct]
+			if !ok {
+				desc = "'" + construct + "'"
+			}
+			violations = append(violations, Violation{
+				Rule:    "requiredConstruct",
+				Message: fmt.Sprintf("Your solution must use %s", desc),
+			})
+		}
+	}
+
+	return violations
+}
+
+// Explain joins violations into a single human readable message.
+func Explain(violations []Violation) string {
+	lines := make([]string, 0, len(violations))
+	for _, v := range violations {
+		if v.Line > 0 {
+			lines = append(lines, fmt.Sprintf("line %d: %s", v.Line, v.Message))
+		} else {
+			lines = append(lines, v.Message)
+		}
+	}
+	return "Rule violation: " + strings.Join(lines, "; ")
+}
+
+// analyze walks the token stream and records which constructs appear.
+// Function definitions are recognised at file scope as
+// `name ( ... ) {`, which is enough for the code students write.
+func analyze(tokens []token) map[string]bool {
+	found := make(map[string]bool)
+	calls := make(map[string]map[string]bool)
+
+	depth := 0
+	current := ""
+	// doWhiles marks the while keywords that close a do statement.
+	doWhiles := make(map[int]bool)
+
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+
+		if tok.kind == tokPunct {
+			switch tok.text {
+			case "{":
+				if depth == 0 {
+					current = functionBefore(tokens, i)
+					if current != "" && current != "main" {
+						found[ConstructFunction] = true
+					}
+				}
+				depth++
+			case "}":
+				depth--
+				if depth <= 0 {
+					depth = 0
+					current = ""
+				}
+			}
+			continue
+		}
+
+		if tok.kind != tokIdent {
+			continue
+		}
+
+		switch tok.text {
+		case "do":
+			if end := statementEnd(tokens, i+1); end+1 < len(tokens) && tokens[end+1].text == "while" {
+				doWhiles[end+1] = true
+			}
+		case "while":
+			if doWhiles[i] {
+				found[ConstructDoWhile] = true
+			} else {
+				found[ConstructWhile] = true
+			}
+		case "for":
+			found[ConstructFor] = true
+		case "if":
+			found[ConstructIf] = true
+		case "switch":
+			found[ConstructSwitch] = true
+		case "struct":
+			if i+2 < len(tokens) && (tokens[i+1].text == "{" || tokens[i+2].text == "{") {
+				found[ConstructStruct] = true
+			}
+		default:
+			if current != "" && i+1 < len(tokens) && tokens[i+1].text == "(" {
+				if calls[current] == nil {
+					calls[current] = make(map[string]bool)
+				}
+				calls[current][tok.text] = true
+			}
+		}
+	}
+
+	if hasCycle(calls) {
+		found[ConstructRecursion] = true
+	}
+	return found
+}
+
+// statementEnd returns the index of the last token of the statement that
+// starts at start, its closing ';' or '}'. It knows just enough C to step
+// over nested blocks and control statements.
+func statementEnd(tokens []token, start int) int {
+	if start >= len(tokens) {
+		return len(tokens) - 1
+	}
+	switch tokens[start].text {
+	case "{":
+		return closingIndex(tokens, start)
+	case "if":
+		end := statementEnd(tokens, afterParens(tokens, start+1))
+		if end+1 < len(tokens) && tokens[end+1].text == "else" {
+			end = statementEnd(tokens, end+2)
+		}
+		return end
+	case "for", "while", "switch":
+		return statementEnd(tokens, afterParens(tokens, start+1))
+	case "do":
+		// The body, then "while (...)" and its ';' as one more statement.
+		return statementEnd(tokens, statementEnd(tokens, start+1)+1)
+	}
+
+	depth := 0
+	for i := start; i < len(tokens); i++ {
+		switch tokens[i].text {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			depth--
+			if depth < 0 {
+				return i - 1
+			}
+		case ";":
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(tokens) - 1
+}
+
+// closingIndex returns the index of the bracket closing the one at open.
+func closingIndex(tokens []token, open int) int {
+	depth := 0
+	for i := open; i < len(tokens); i++ {
+		switch tokens[i].text {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(tokens) - 1
+}
+
+// afterParens skips the parenthesised group starting at i, if there is one.
+func afterParens(tokens []token, i int) int {
+	if i < len(tokens) && tokens[i].text == "(" {
+		return closingIndex(tokens, i) + 1
+	}
+	return i
+}
+
+// functionBefore returns the function name if the brace at index open starts
+// a function body, or "" otherwise.
+func functionBefore(tokens []token, open int) string {
+	i := open - 1
+	if i < 0 || tokens[i].text != ")" {
+		return ""
+	}
+
+	parens := 0
+	for ; i >= 0; i-- {
+		switch tokens[i].text {
+		case ")":
+			parens++
+		case "(":
+			parens--
+		}
+		if parens == 0 {
+			break
+		}
+	}
+	if i <= 0 || tokens[i-1].kind != tokIdent {
+		return ""
+	}
+	return tokens[i-1].text
+}
+
+// hasCycle reports whether the call graph between defined functions
+// contains a cycle, which covers both direct and mutual recursion.
+func hasCycle(calls map[string]map[string]bool) bool {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int)
+
+	var visit func(string) bool
+	visit = func(fn string) bool {
+		state[fn] = visiting
+		callees := make([]string, 0, len(calls[fn]))
+		for callee := range calls[fn] {
+			callees = append(callees, callee)
+		}
+		sort.Strings(callees)
+		for _, callee := range callees {
+			if _, defined := calls[callee]; !defined && callee != fn {
+				continue
+			}
+			switch state[callee] {
+			case visiting:
+				return true
+			case unvisited:
+				if visit(callee) {
+					return true
+				}
+			}
+		}
+		state[fn] = done
+		return false
+	}
+
+	for fn := range calls {
+		if state[fn] == unvisited && visit(fn) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,357 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+
+	"strathlearn/backend/models"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []token
+	}{
+		{
+			name: "line comment",
+			src:  "int x; // printf(\"hi\");\nint y;",
+			want: []token{
+				{tokIdent, "int", 1}, {tokIdent, "x", 1}, {tokPunct, ";", 1},
+				{tokIdent, "int", 2}, {tokIdent, "y", 2}, {tokPunct, ";", 2},
+			},
+		},
+		{
+			name: "block comment spanning lines",
+			src:  "a /* printf\n malloc */ b",
+			want: []token{{tokIdent, "a", 1}, {tokIdent, "b", 2}},
+		},
+		{
+			name: "unterminated block comment",
+			src:  "a /* never closed",
+			want: []token{{tokIdent, "a", 1}},
+		},
+		{
+			name: "string with escaped quote",
+			src:  `puts("say \"printf\"");`,
+			want: []token{
+				{tokIdent, "puts", 1}, {tokPunct, "(", 1},
+				{tokString, `"say \"printf\""`, 1},
+				{tokPunct, ")", 1}, {tokPunct, ";", 1},
+			},
+		},
+		{
+			name: "comment markers inside a string",
+			src:  `s = "/* not a comment */";`,
+			want: []token{
+				{tokIdent, "s", 1}, {tokPunct, "=", 1},
+				{tokString, `"/* not a comment */"`, 1}, {tokPunct, ";", 1},
+			},
+		},
+		{
+			name: "char literals",
+			src:  `c = '\''; d = '"';`,
+			want: []token{
+				{tokIdent, "c", 1}, {tokPunct, "=", 1}, {tokChar, `'\''`, 1}, {tokPunct, ";", 1},
+				{tokIdent, "d", 1}, {tokPunct, "=", 1}, {tokChar, `'"'`, 1}, {tokPunct, ";", 1},
+			},
+		},
+		{
+			name: "line continuation",
+			src:  "#define SAY \\\n  puts\nint",
+			want: []token{
+				{tokPunct, "#", 1}, {tokIdent, "define", 1}, {tokIdent, "SAY", 1},
+				{tokIdent, "puts", 2}, {tokIdent, "int", 3},
+			},
+		},
+		{
+			name: "includes",
+			src:  "#include <stdio.h>\n#  include \"list.h\"\n#include HEADER\n",
+			want: []token{
+				{tokInclude, "stdio.h", 1},
+				{tokInclude, "list.h", 2},
+				{tokMacroInclude, "HEADER", 3},
+			},
+		},
+		{
+			name: "token pasting",
+			src:  "pr ## intf",
+			want: []token{{tokIdent, "pr", 1}, {tokPunct, "##", 1}, {tokIdent, "intf", 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tokenize(tt.src); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokenize(%q) =\n%v\nwant\n%v", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+// ruleNames returns the rule of each violation, in order.
+func ruleNames(violations []Violation) []string {
+	names := make([]string, 0, len(violations))
+	for _, v := range violations {
+		names = append(names, v.Rule)
+	}
+	return names
+}
+
+func TestCheckBannedIdentifiers(t *testing.T) {
+	rules := &models.CodeRules{BannedIdentifiers: []string{"printf", "strlen"}}
+
+	tests := []struct {
+		name string
+		code string
+		want []string
+		line int
+	}{
+		{
+			name: "call is banned",
+			code: "int main() {\n  printf(\"hi\");\n}",
+			want: []string{"bannedIdentifier"},
+			line: 2,
+		},
+		{
+			name: "inside a string is allowed",
+			code: `int main() { puts("printf and strlen"); }`,
+		},
+		{
+			name: "inside a char literal or comment is allowed",
+			code: "int main() { char c = 'p'; /* strlen */ // printf\n}",
+		},
+		{
+			name: "reported once per identifier",
+			code: "int main() { printf(\"a\"); printf(\"b\"); return strlen(\"c\"); }",
+			want: []string{"bannedIdentifier", "bannedIdentifier"},
+			line: 1,
+		},
+		{
+			name: "hidden behind a macro",
+			code: "#define SAY printf\nint main() { SAY(\"hi\"); }",
+			want: []string{"bannedIdentifier"},
+			line: 1,
+		},
+		{
+			name: "assembled by token pasting",
+			code: "#define CAT(a, b) a ## b\nint main() { CAT(pri, ntf)(\"hi\"); }",
+			want: []string{"tokenPasting"},
+			line: 1,
+		},
+		{
+			name: "longer identifier is allowed",
+			code: `int main() { my_printf("hi"); }`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := Check(tt.code, rules)
+			if got := ruleNames(violations); !reflect.DeepEqual(got, append([]string{}, tt.want...)) {
+				t.Fatalf("violations = %+v, want rules %v", violations, tt.want)
+			}
+			if len(violations) > 0 && violations[0].Line != tt.line {
+				t.Errorf("line = %d, want %d", violations[0].Line, tt.line)
+			}
+		})
+	}
+}
+
+func TestCheckTokenPastingAllowedWithoutBannedIdentifiers(t *testing.T) {
+	code := "#define CAT(a, b) a ## b\nint main() { return CAT(1, 0); }"
+	if violations := Check(code, &models.CodeRules{BannedHeaders: []string{"string.h"}}); len(violations) != 0 {
+		t.Errorf("violations = %+v, want none", violations)
+	}
+}
+
+func TestCheckBannedHeaders(t *testing.T) {
+	rules := &models.CodeRules{BannedHeaders: []string{"stdlib.h", "string.h"}}
+
+	tests := []struct {
+		name string
+		code string
+		want []string
+	}{
+		{
+			name: "angle brackets",
+			code: "#include <stdio.h>\n#include <stdlib.h>\n",
+			want: []string{"bannedHeader"},
+		},
+		{
+			name: "quotes",
+			code: "#include \"string.h\"\n",
+			want: []string{"bannedHeader"},
+		},
+		{
+			name: "allowed header",
+			code: "#include <stdio.h>\n",
+		},
+		{
+			name: "header named by a macro",
+			code: "#define H <stdlib.h>\n#include H\n",
+			want: []string{"bannedHeader"},
+		},
+		{
+			name: "header named by a macro of an allowed header",
+			code: "#define H <stdio.h>\n#include H\n",
+			want: []string{"bannedHeader"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := Check(tt.code, rules)
+			if got := ruleNames(violations); !reflect.DeepEqual(got, append([]string{}, tt.want...)) {
+				t.Errorf("violations = %+v, want rules %v", violations, tt.want)
+			}
+		})
+	}
+
+	if violations := Check("#define H <stdlib.h>\n#include H\n", &models.CodeRules{}); len(violations) != 0 {
+		t.Errorf("macro include without banned headers: violations = %+v, want none", violations)
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want []string
+	}{
+		{
+			name: "while loop",
+			code: "int main() { int i = 0; while (i < 3) i++; }",
+			want: []string{ConstructWhile},
+		},
+		{
+			name: "do-while is not a while loop",
+			code: "int main() { int i = 0; do { i++; } while (i < 3); }",
+			want: []string{ConstructDoWhile},
+		},
+		{
+			name: "do-while with an inner while",
+			code: "int main() { do { while (0) {} } while (0); }",
+			want: []string{ConstructDoWhile, ConstructWhile},
+		},
+		{
+			name: "for, if and switch",
+			code: "int main() { for (;;) { if (1) break; } switch (0) { default: break; } }",
+			want: []string{ConstructFor, ConstructIf, ConstructSwitch},
+		},
+		{
+			name: "keywords in strings and comments",
+			code: "int main() { puts(\"while for if\"); /* switch */ }",
+		},
+		{
+			name: "struct definition, not a declaration",
+			code: "struct point { int x; };\nint main() { struct point p; }",
+			want: []string{ConstructStruct},
+		},
+		{
+			name: "struct declaration only",
+			code: "int main() { struct point *p = 0; }",
+		},
+		{
+			name: "helper function",
+			code: "int twice(int n) { return n * 2; }\nint main() { return twice(1); }",
+			want: []string{ConstructFunction},
+		},
+		{
+			name: "direct recursion",
+			code: "int fact(int n) { if (n < 2) return 1; return n * fact(n - 1); }\nint main() { return fact(3); }",
+			want: []string{ConstructFunction, ConstructIf, ConstructRecursion},
+		},
+		{
+			name: "mutual recursion",
+			code: "int odd(int n);\nint even(int n) { return n == 0 ? 1 : odd(n - 1); }\n" +
+				"int odd(int n) { return n == 0 ? 0 : even(n - 1); }\nint main() { return even(4); }",
+			want: []string{ConstructFunction, ConstructRecursion},
+		},
+		{
+			name: "calls without a cycle",
+			code: "int a(void) { return 1; }\nint b(void) { return a(); }\nint main() { return b(); }",
+			want: []string{ConstructFunction},
+		},
+		{
+			name: "recursive main",
+			code: "int main() { return main(); }",
+			want: []string{ConstructRecursion},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := make(map[string]bool, len(tt.want))
+			for _, c := range tt.want {
+				want[c] = true
+			}
+			if got := analyze(tokenize(tt.code)); !reflect.DeepEqual(got, want) {
+				t.Errorf("analyze = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestHasCycle(t *testing.T) {
+	tests := []struct {
+		name  string
+		calls map[string]map[string]bool
+		want  bool
+	}{
+		{
+			name:  "no calls",
+			calls: map[string]map[string]bool{"main": {}},
+		},
+		{
+			name:  "calls a library function",
+			calls: map[string]map[string]bool{"main": {"printf": true}},
+		},
+		{
+			name:  "self call",
+			calls: map[string]map[string]bool{"f": {"f": true}},
+			want:  true,
+		},
+		{
+			name: "three function cycle",
+			calls: map[string]map[string]bool{
+				"main": {"a": true},
+				"a":    {"b": true},
+				"b":    {"c": true},
+				"c":    {"a": true},
+			},
+			want: true,
+		},
+		{
+			name: "diamond",
+			calls: map[string]map[string]bool{
+				"main": {"a": true, "b": true},
+				"a":    {"c": true},
+				"b":    {"c": true},
+				"c":    {},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasCycle(tt.calls); got != tt.want {
+				t.Errorf("hasCycle = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckRequiredConstructs(t *testing.T) {
+	rules := &models.CodeRules{RequiredConstructs: []string{ConstructRecursion, ConstructFor}}
+	code := "int sum(int n) { return n == 0 ? 0 : n + sum(n - 1); }\nint main() { return sum(3); }"
+
+	violations := Check(code, rules)
+	if len(violations) != 1 || violations[0].Rule != "requiredConstruct" ||
+		violations[0].Message != "Your solution must use a for loop" {
+		t.Errorf("violations = %+v, want only the missing for loop", violations)
+	}
+	if Check(code, nil) != nil {
+		t.Error("nil rules should never report violations")
+	}
+}
@@ -0,0 +1,162 @@
+package rules
+
+import (
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokIdent tokenKind = iota
+	tokNumber
+	tokString
+	tokChar
+	tokPunct
+	tokInclude
+	// tokMacroInclude is an #include whose header comes from a macro, so
+	// the header it names cannot be known without preprocessing.
+	tokMacroInclude
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+// tokenize splits C source into tokens, dropping comments and whitespace.
+// It is deliberately forgiving: malformed input still yields tokens so that
+// rule checks can report something useful before the compiler runs.
+func tokenize(src string) []token {
+	var tokens []token
+	line := 1
+	atLineStart := true
+	i := 0
+
+	for i < len(src) {
+		c := src[i]
+
+		switch {
+		case c == '\n':
+			line++
+			atLineStart = true
+			i++
+			continue
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+			i++
+			continue
+		case c == '\\' && i+1 < len(src) && src[i+1] == '\n':
+			// Line continuation.
+			line++
+			i += 2
+			continue
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			continue
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				end = len(src) - i - 2
+			}
+			line += strings.Count(src[i:i+2+end], "\n")
+			i += end + 4
+			continue
+		}
+
+		if c == '#' && atLineStart {
+			if name, macro, n := parseInclude(src[i:]); n > 0 {
+				kind := tokInclude
+				if macro {
+					kind = tokMacroInclude
+				}
+				tokens = append(tokens, token{kind: kind, text: name, line: line})
+				i += n
+				continue
+			}
+			// Other directives are tokenized normally so that macro
+			// bodies are still subject to the identifier rules.
+			tokens = append(tokens, token{kind: tokPunct, text: "#", line: line})
+			i++
+			atLineStart = false
+			continue
+		}
+		atLineStart = false
+
+		switch {
+		case c == '_' || unicode.IsLetter(rune(c)):
+			start := i
+			for i < len(src) && (src[i] == '_' || unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i]))) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: src[start:i], line: line})
+		case unicode.IsDigit(rune(c)):
+			start := i
+			for i < len(src) && (src[i] == '.' || src[i] == '_' || unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i]))) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: src[start:i], line: line})
+		case c == '"' || c == '\'':
+			start := i
+			i++
+			for i < len(src) && src[i] != c && src[i] != '\n' {
+				if src[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			if i < len(src) && src[i] == c {
+				i++
+			}
+			if i > len(src) {
+				i = len(src)
+			}
+			kind := tokString
+			if c == '\'' {
+				kind = tokChar
+			}
+			tokens = append(tokens, token{kind: kind, text: src[start:i], line: line})
+		case strings.HasPrefix(src[i:], "##"):
+			tokens = append(tokens, token{kind: tokPunct, text: "##", line: line})
+			i += 2
+		default:
+			tokens = append(tokens, token{kind: tokPunct, text: string(c), line: line})
+			i++
+		}
+	}
+
+	return tokens
+}
+
+// parseInclude recognises an #include directive at the start of s and
+// returns the header name and the number of bytes consumed. When the header
+// is not written as <name> or "name" it is a macro, and macro is true with
+// the rest of the directive as name.
+func parseInclude(s string) (name string, macro bool, n int) {
+	end := strings.IndexByte(s, '\n')
+	if end < 0 {
+		end = len(s)
+	}
+	directive := strings.TrimSpace(s[1:end])
+	if !strings.HasPrefix(directive, "include") {
+		return "", false, 0
+	}
+
+	header := strings.TrimSpace(strings.TrimPrefix(directive, "include"))
+	if header == "" {
+		return "", false, 0
+	}
+	switch header[0] {
+	case '<':
+		if close := strings.IndexByte(header, '>'); close > 0 {
+			return header[1:close], false, end
+		}
+	case '"':
+		if close := strings.IndexByte(header[1:], '"'); close >= 0 {
+			return header[1 : close+1], false, end
+		}
+	}
+	return header, true, end
+}
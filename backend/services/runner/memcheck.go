@@ -0,0 +1,201 @@
+package runner
+
+import (
+	"encoding/xml"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+
+	"github.com/docker/docker/client"
+
+	"strathlearn/backend/models"
+)
+
+// Valgrind runs far slower than native code, so memcheck runs get a
+// proportionally larger time budget.
+const memcheckTimeMultiplier = 10
+
+var memcheckArgs = []string{
+	"valgrind",
+	"--tool=memcheck",
+	"--quiet",
+	"--leak-check=full",
+	"--show-leak-kinds=definite,indirect,possible",
+	"--track-origins=yes",
+	"--xml=yes",
+}
+
+type valgrindOutput struct {
+	XMLName xml.Name        `xml:"valgrindoutput"`
+	Status  []string        `xml:"status>state"`
+	Errors  []valgrindError `xml:"error"`
+}
+
+type valgrindError struct {
+	Kind  string `xml:"kind"`
+	What  string `xml:"what"`
+	XWhat struct {
+		Text         string `xml:"text"`
+		LeakedBytes  int    `xml:"leakedbytes"`
+		LeakedBlocks int    `xml:"leakedblocks"`
+	} `xml:"xwhat"`
+	Frames []struct {
+		Fn   string `xml:"fn"`
+		File string `xml:"file"`
+		Line int    `xml:"line"`
+	} `xml:"stack>frame"`
+}
+
+// MemcheckRouter sends challenges that need a memory check to a runner
+// with Valgrind and everything else to the default runner, which for
+// Judge0 cannot run Valgrind at all.
+type MemcheckRouter struct {
+	runner   CodeRunner
+	memcheck CodeRunner
+}
+
+// NewMemcheckRouter wraps runner so memcheck challenges run on memcheck.
+// With a nil memcheck runner those challenges fall through to runner,
+// which reports a system error rather than judging them unchecked.
+func NewMemcheckRouter(runner, memcheck CodeRunner) *MemcheckRouter {
+	return &MemcheckRouter{runner: runner, memcheck: memcheck}
+}
+
+func (r *MemcheckRouter) RunTests(code string, challenge models.Challenge) []models.TestResult {
+	if challenge.Memcheck && r.memcheck != nil {
+		return r.memcheck.RunTests(code, challenge)
+	}
+	return r.runner.RunTests(code, challenge)
+}
+
+func (r *MemcheckRouter) IsDockerAvailable() bool {
+	return r.runner.IsDockerAvailable()
+}
+
+// CanMemcheck reports whether memcheck challenges have a runner that can
+// actually run Valgrind.
+func (r *MemcheckRouter) CanMemcheck() bool {
+	return r.memcheck != nil
+}
+
+// NewMemcheckRunner returns a runner that can judge memcheck challenges:
+// Docker when a client is available, since the runner image ships
+// Valgrind, or the local runner when gcc and valgrind are installed.
+// It returns nil when neither can.
+func NewMemcheckRunner(dockerClient *client.Client) CodeRunner {
+	if dockerClient != nil {
+		return NewDockerRunner(dockerClient)
+	}
+	for _, tool := range []string{"gcc", "valgrind"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			return nil
+		}
+	}
+	return NewLocalRunner()
+}
+
+// memcheckArgv returns the command line that runs binary under Valgrind.
+// xmlOption selects where the XML report goes, e.g. "--xml-file=out.xml".
+func memcheckArgv(binary, xmlOption string) []string {
+	args := append([]string{}, memcheckArgs...)
+	return append(args, xmlOption, binary)
+}
+
+// ParseMemcheckXML converts Valgrind's XML output into a report.
+func ParseMemcheckXML(data []byte) (*models.MemcheckReport, error) {
+	var out valgrindOutput
+	if err := xml.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("error parsing valgrind XML: %w", err)
+	}
+	// Valgrind writes a FINISHED status last; without it the program was
+	// killed part way and the error list cannot be trusted.
+	if len(out.Status) == 0 || out.Status[len(out.Status)-1] != "FINISHED" {
+		return nil, fmt.Errorf("valgrind XML has no FINISHED status")
+	}
+
+	report := &models.MemcheckReport{}
+	for _, e := range out.Errors {
+		entry := models.MemcheckError{Kind: e.Kind, What: e.What}
+		if entry.What == "" {
+			entry.What = e.XWhat.Text
+		}
+		for _, frame := range e.Frames {
+			if frame.File != "" && !strings.HasPrefix(frame.File, "vg_") {
+				entry.File = frame.File
+				entry.Line = frame.Line
+				break
+			}
+		}
+
+		switch {
+		case strings.HasPrefix(e.Kind, "Leak_"):
+			if e.Kind == "Leak_StillReachable" {
+				continue
+			}
+			entry.LeakedBytes = e.XWhat.LeakedBytes
+			report.LeakedBytes += e.XWhat.LeakedBytes
+			report.LeakedBlocks += e.XWhat.LeakedBlocks
+		case e.Kind == "InvalidRead":
+			report.InvalidReads++
+		case e.Kind == "InvalidWrite":
+			report.InvalidWrites++
+		case e.Kind == "InvalidFree" || e.Kind == "MismatchedFree":
+			report.InvalidFrees++
+		case strings.HasPrefix(e.Kind, "Uninit") || e.Kind == "SyscallParam":
+			report.Uninitialised++
+		}
+		report.Errors = append(report.Errors, entry)
+	}
+
+	return report, nil
+}
+
+// applyMemcheck marks an otherwise passing result as a memory error when
+// Valgrind reported leaks or invalid accesses.
+func applyMemcheck(result *models.TestResult, report *models.MemcheckReport) {
+	result.Memcheck = report
+	if report == nil || len(report.Errors) == 0 || !result.Passed {
+		return
+	}
+
+	result.Passed = false
+	result.Verdict = models.VerdictMemoryError
+	result.Error = memcheckSummary(report)
+}
+
+// failMemcheck fails a passing result whose memcheck report could not be
+// read, so a challenge that requires memcheck never passes without one.
+func failMemcheck(result *models.TestResult, err error) {
+	log.Printf("Memcheck failed for test %s: %v", result.TestCaseID, err)
+	if !result.Passed {
+		return
+	}
+	result.Passed = false
+	result.Verdict = models.VerdictSystemError
+	result.Error = "System error: memory check did not run"
+}
+
+func memcheckSummary(report *models.MemcheckReport) string {
+	var parts []string
+	if report.LeakedBytes > 0 {
+		parts = append(parts, fmt.Sprintf("%d bytes leaked in %d blocks",
+			report.LeakedBytes, report.LeakedBlocks))
+	}
+	if report.InvalidReads > 0 {
+		parts = append(parts, fmt.Sprintf("%d invalid reads", report.InvalidReads))
+	}
+	if report.InvalidWrites > 0 {
+		parts = append(parts, fmt.Sprintf("%d invalid writes", report.InvalidWrites))
+	}
+	if report.InvalidFrees > 0 {
+		parts = append(parts, fmt.Sprintf("%d invalid frees", report.InvalidFrees))
+	}
+	if report.Uninitialised > 0 {
+		parts = append(parts, fmt.Sprintf("%d uses of uninitialised values", report.Uninitialised))
+	}
+	if len(parts) == 0 {
+		parts = append(parts, fmt.Sprintf("%d memory errors", len(report.Errors)))
+	}
+	return "Memory error: " + strings.Join(parts, ", ")
+}
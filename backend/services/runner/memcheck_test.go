@@ -0,0 +1,314 @@
+package runner
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"strathlearn/backend/models"
+)
+
+const valgrindHeader = `<?xml version="1.0"?>
+
+<valgrindoutput>
+
+<protocolversion>4</protocolversion>
+<protocoltool>memcheck</protocoltool>
+
+<preamble>
+  <line>Memcheck, a memory error detector</line>
+  <line>Copyright (C) 2002-2022, and GNU GPL'd, by Julian Seward et al.</line>
+  <line>Using Valgrind-3.19.0 and LibVEX; rerun with -h for copyright info</line>
+  <line>Command: ./solution</line>
+</preamble>
+
+<pid>4211</pid>
+<ppid>4210</ppid>
+<tool>memcheck</tool>
+
+<args>
+  <vargv>
+    <exe>/usr/bin/valgrind</exe>
+    <arg>--tool=memcheck</arg>
+    <arg>--leak-check=full</arg>
+    <arg>--xml=yes</arg>
+    <arg>--xml-file=memcheck.xml</arg>
+  </vargv>
+  <argv>
+    <exe>./solution</exe>
+  </argv>
+</args>
+
+<status>
+  <state>RUNNING</state>
+  <time>00:00:00:00.044 </time>
+</status>
+
+`
+
+const valgrindFooter = `
+<status>
+  <state>FINISHED</state>
+  <time>00:00:00:00.612 </time>
+</status>
+
+<errorcounts>
+</errorcounts>
+
+<suppcounts>
+</suppcounts>
+
+</valgrindoutput>
+`
+
+const valgrindLeak = `<error>
+  <unique>0x0</unique>
+  <tid>1</tid>
+  <kind>Leak_DefinitelyLost</kind>
+  <xwhat>
+    <text>40 bytes in 1 blocks are definitely lost in loss record 1 of 2</text>
+    <leakedbytes>40</leakedbytes>
+    <leakedblocks>1</leakedblocks>
+  </xwhat>
+  <stack>
+    <frame>
+      <ip>0x48407B4</ip>
+      <obj>/usr/libexec/valgrind/vgpreload_memcheck-amd64-linux.so</obj>
+      <fn>malloc</fn>
+      <dir>/builddir/build/BUILD/valgrind-3.19.0/coregrind/m_replacemalloc</dir>
+      <file>vg_replace_malloc.c</file>
+      <line>381</line>
+    </frame>
+    <frame>
+      <ip>0x109176</ip>
+      <obj>/code/solution</obj>
+      <fn>main</fn>
+      <dir>/code</dir>
+      <file>solution.c</file>
+      <line>6</line>
+    </frame>
+  </stack>
+</error>
+
+<error>
+  <unique>0x1</unique>
+  <tid>1</tid>
+  <kind>Leak_StillReachable</kind>
+  <xwhat>
+    <text>1,024 bytes in 1 blocks are still reachable in loss record 2 of 2</text>
+    <leakedbytes>1024</leakedbytes>
+    <leakedblocks>1</leakedblocks>
+  </xwhat>
+  <stack>
+    <frame>
+      <ip>0x48407B4</ip>
+      <fn>malloc</fn>
+      <file>vg_replace_malloc.c</file>
+      <line>381</line>
+    </frame>
+  </stack>
+</error>
+`
+
+const valgrindInvalidRead = `<error>
+  <unique>0x0</unique>
+  <tid>1</tid>
+  <kind>InvalidRead</kind>
+  <what>Invalid read of size 4</what>
+  <stack>
+    <frame>
+      <ip>0x1091A3</ip>
+      <obj>/code/solution</obj>
+      <fn>main</fn>
+      <dir>/code</dir>
+      <file>solution.c</file>
+      <line>9</line>
+    </frame>
+  </stack>
+  <auxwhat>Address 0x4a9b068 is 0 bytes after a block of size 40 alloc'd</auxwhat>
+  <stack>
+    <frame>
+      <ip>0x48407B4</ip>
+      <fn>malloc</fn>
+      <file>vg_replace_malloc.c</file>
+      <line>381</line>
+    </frame>
+  </stack>
+</error>
+`
+
+func TestParseMemcheckXML(t *testing.T) {
+	tests := []struct {
+		name    string
+		xml     string
+		wantErr bool
+		want    models.MemcheckReport
+		file    string
+		line    int
+	}{
+		{
+			name: "clean",
+			xml:  valgrindHeader + valgrindFooter,
+		},
+		{
+			name: "leak",
+			xml:  valgrindHeader + valgrindLeak + valgrindFooter,
+			want: models.MemcheckReport{LeakedBytes: 40, LeakedBlocks: 1},
+			file: "solution.c",
+			line: 6,
+		},
+		{
+			name: "invalid read",
+			xml:  valgrindHeader + valgrindInvalidRead + valgrindFooter,
+			want: models.MemcheckReport{InvalidReads: 1},
+			file: "solution.c",
+			line: 9,
+		},
+		{
+			name:    "truncated",
+			xml:     valgrindHeader + valgrindInvalidRead[:200],
+			wantErr: true,
+		},
+		{
+			name:    "killed before finishing",
+			xml:     valgrindHeader + valgrindInvalidRead + "</valgrindoutput>\n",
+			wantErr: true,
+		},
+		{
+			name:    "empty",
+			xml:     "",
+			wantErr: true,
+		},
+		{
+			name:    "not valgrind",
+			xml:     "<html><body>Segmentation fault</body></html>",
+			wantErr: true,
+		},
+		{
+			name:    "malformed",
+			xml:     "<valgrindoutput><error><kind>InvalidRead</error></valgrindoutput>",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := ParseMemcheckXML([]byte(tt.xml))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got report %+v", report)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseMemcheckXML: %v", err)
+			}
+
+			if report.LeakedBytes != tt.want.LeakedBytes || report.LeakedBlocks != tt.want.LeakedBlocks ||
+				report.InvalidReads != tt.want.InvalidReads || report.InvalidWrites != tt.want.InvalidWrites {
+				t.Errorf("report = %+v, want %+v", report, tt.want)
+			}
+			if tt.file == "" {
+				if len(report.Errors) != 0 {
+					t.Errorf("expected no errors, got %+v", report.Errors)
+				}
+				return
+			}
+			if len(report.Errors) != 1 {
+				t.Fatalf("expected 1 error, got %+v", report.Errors)
+			}
+			if got := report.Errors[0]; got.File != tt.file || got.Line != tt.line {
+				t.Errorf("error located at %s:%d, want %s:%d", got.File, got.Line, tt.file, tt.line)
+			}
+		})
+	}
+}
+
+func TestApplyMemcheck(t *testing.T) {
+	report, err := ParseMemcheckXML([]byte(valgrindHeader + valgrindLeak + valgrindFooter))
+	if err != nil {
+		t.Fatalf("ParseMemcheckXML: %v", err)
+	}
+
+	result := models.TestResult{TestCaseID: "t1", Passed: true, Verdict: models.VerdictAccepted}
+	applyMemcheck(&result, report)
+	if result.Passed || result.Verdict != models.VerdictMemoryError {
+		t.Fatalf("leaking result = %+v, want a memory error", result)
+	}
+	if !strings.Contains(result.Error, "40 bytes leaked in 1 blocks") {
+		t.Errorf("error = %q, want the leak summary", result.Error)
+	}
+
+	clean := models.TestResult{TestCaseID: "t2", Passed: true, Verdict: models.VerdictAccepted}
+	applyMemcheck(&clean, &models.MemcheckReport{})
+	if !clean.Passed || clean.Verdict != models.VerdictAccepted {
+		t.Errorf("clean result = %+v, want it to stay accepted", clean)
+	}
+}
+
+func TestFailMemcheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		result models.TestResult
+		want   models.TestResult
+	}{
+		{
+			name:   "passing result fails closed",
+			result: models.TestResult{TestCaseID: "t1", Passed: true, Verdict: models.VerdictAccepted},
+			want: models.TestResult{TestCaseID: "t1", Passed: false, Verdict: models.VerdictSystemError,
+				Error: "System error: memory check did not run"},
+		},
+		{
+			name: "failing result keeps its verdict",
+			result: models.TestResult{TestCaseID: "t2", Passed: false, Verdict: models.VerdictWrongAnswer,
+				Error: "Wrong answer"},
+			want: models.TestResult{TestCaseID: "t2", Passed: false, Verdict: models.VerdictWrongAnswer,
+				Error: "Wrong answer"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.result
+			failMemcheck(&result, errors.New("valgrind XML has no FINISHED status"))
+			if result.Passed != tt.want.Passed || result.Verdict != tt.want.Verdict || result.Error != tt.want.Error {
+				t.Errorf("result = %+v, want %+v", result, tt.want)
+			}
+		})
+	}
+}
+
+type recordingRunner struct {
+	ran []string
+}
+
+func (r *recordingRunner) RunTests(code string, challenge models.Challenge) []models.TestResult {
+	r.ran = append(r.ran, challenge.ID)
+	return nil
+}
+
+func (r *recordingRunner) IsDockerAvailable() bool { return false }
+
+func TestMemcheckRouter(t *testing.T) {
+	judge, memcheck := &recordingRunner{}, &recordingRunner{}
+	router := NewMemcheckRouter(judge, memcheck)
+
+	router.RunTests("", models.Challenge{ID: "plain"})
+	router.RunTests("", models.Challenge{ID: "leaky", Memcheck: true})
+
+	if len(judge.ran) != 1 || judge.ran[0] != "plain" {
+		t.Errorf("judge ran %v, want [plain]", judge.ran)
+	}
+	if len(memcheck.ran) != 1 || memcheck.ran[0] != "leaky" {
+		t.Errorf("memcheck runner ran %v, want [leaky]", memcheck.ran)
+	}
+
+	fallback := NewMemcheckRouter(judge, nil)
+	if fallback.CanMemcheck() {
+		t.Error("router without a memcheck runner claims it can memcheck")
+	}
+	fallback.RunTests("", models.Challenge{ID: "leaky", Memcheck: true})
+	if got := judge.ran[len(judge.ran)-1]; got != "leaky" {
+		t.Errorf("without a memcheck runner the judge ran %q, want leaky", got)
+	}
+}
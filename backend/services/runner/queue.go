@@ -0,0 +1,49 @@
+package runner
+
+import (
+	"strathlearn/backend/models"
+)
+
+// Queue runs judging jobs on a fixed number of workers so that bursts of
+// work, such as rejudging a whole challenge, cannot flood the runner. It is
+// itself a CodeRunner and blocks callers until their job has run.
+type Queue struct {
+	runner CodeRunner
+	jobs   chan queueJob
+}
+
+type queueJob struct {
+	code      string
+	challenge models.Challenge
+	done      chan []models.TestResult
+}
+
+func NewQueue(runner CodeRunner, workers int) *Queue {
+	if workers < 1 {
+		workers = 1
+	}
+	q := &Queue{
+		runner: runner,
+		jobs:   make(chan queueJob),
+	}
+	for i := 0; i < workers; i++ {
+		go q.work()
+	}
+	return q
+}
+
+func (q *Queue) work() {
+	for job := range q.jobs {
+		job.done <- q.runner.RunTests(job.code, job.challenge)
+	}
+}
+
+func (q *Queue) RunTests(code string, challenge models.Challenge) []models.TestResult {
+	done := make(chan []models.TestResult, 1)
+	q.jobs <- queueJob{code: code, challenge: challenge, done: done}
+	return <-done
+}
+
+func (q *Queue) IsDockerAvailable() bool {
+	return q.runner.IsDockerAvailable()
+}
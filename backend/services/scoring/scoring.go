@@ -0,0 +1,189 @@
+package scoring
+
+import (
+	"strathlearn/backend/models"
+)
+
+// Subtasks returns the subtasks used to score a challenge. Challenges that
+// do not declare any are scored one point per test case.
+func Subtasks(challenge models.Challenge) []models.Subtask {
+	if len(challenge.Subtasks) > 0 {
+		return challenge.Subtasks
+	}
+
+	subtasks := make([]models.Subtask, 0, len(challenge.TestCases))
+	for _, tc := range challenge.TestCases {
+		subtasks = append(subtasks, models.Subtask{
+			ID:        tc.ID,
+			Points:    1,
+			TestCases: []string{tc.ID},
+		})
+	}
+	return subtasks
+}
+
+// Plan tracks subtask failures while tests run so that runners can skip the
+// remaining tests of a subtask that can no longer earn points.
+type Plan struct {
+	subtasks []models.Subtask
+	index    map[string]int
+	byTest   map[string][]int
+	failed   []bool
+}
+
+func NewPlan(challenge models.Challenge) *Plan {
+	subtasks := Subtasks(challenge)
+	p := &Plan{
+		subtasks: subtasks,
+		index:    make(map[string]int, len(subtasks)),
+		byTest:   make(map[string][]int),
+		failed:   make([]bool, len(subtasks)),
+	}
+	for i, st := range subtasks {
+		p.index[st.ID] = i
+		for _, id := range st.TestCases {
+			p.byTest[id] = append(p.byTest[id], i)
+		}
+	}
+	return p
+}
+
+// Skip reports whether the test can be skipped because every subtask it
+// belongs to has already lost its points.
+func (p *Plan) Skip(testID string) bool {
+	owners := p.byTest[testID]
+	if len(owners) == 0 {
+		return false
+	}
+	for _, i := range owners {
+		if !p.lost(i, make(map[int]bool)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Record notes the outcome of a test.
+func (p *Plan) Record(result models.TestResult) {
+	if result.Passed {
+		return
+	}
+	for _, i := range p.byTest[result.TestCaseID] {
+		p.failed[i] = true
+	}
+}
+
+// SkippedResult is the result reported for a test the plan skipped.
+func SkippedResult(testID string) models.TestResult {
+	return models.TestResult{
+		TestCaseID: testID,
+		Passed:     false,
+		Verdict:    models.VerdictSkipped,
+		Error:      "Skipped after an earlier failure in the same subtask",
+	}
+}
+
+func (p *Plan) lost(i int, seen map[int]bool) bool {
+	if p.failed[i] {
+		return true
+	}
+	if seen[i] {
+		return false
+	}
+	seen[i] = true
+	for _, dep := range p.subtasks[i].DependsOn {
+		if j, ok := p.index[dep]; ok && p.lost(j, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// Score computes the points earned by a set of results.
+func Score(challenge models.Challenge, results []models.TestResult) (int, int, []models.SubtaskResult) {
+	passed := make(map[string]bool, len(results))
+	for _, r := range results {
+		if r.Passed {
+			passed[r.TestCaseID] = true
+		}
+	}
+
+	subtasks := Subtasks(challenge)
+	index := make(map[string]int, len(subtasks))
+	for i, st := range subtasks {
+		index[st.ID] = i
+	}
+
+	// earned is memoised per subtask: 0 unknown, 1 earned, -1 lost.
+	earned := make([]int, len(subtasks))
+	var earns func(i int, seen map[int]bool) bool
+	earns = func(i int, seen map[int]bool) bool {
+		if earned[i] != 0 {
+			return earned[i] > 0
+		}
+		if seen[i] {
+			// A dependency cycle can never be satisfied.
+			return false
+		}
+		seen[i] = true
+
+		ok := len(subtasks[i].TestCases) > 0
+		for _, id := range subtasks[i].TestCases {
+			if !passed[id] {
+				ok = false
+				break
+			}
+		}
+		for _, dep := range subtasks[i].DependsOn {
+			if !ok {
+				break
+			}
+			if j, found := index[dep]; found {
+				ok = earns(j, seen)
+			}
+		}
+
+		earned[i] = -1
+		if ok {
+			earned[i] = 1
+		}
+		return ok
+	}
+
+	score, maxScore := 0, 0
+	breakdown := make([]models.SubtaskResult, 0, len(subtasks))
+	for i, st := range subtasks {
+		result := models.SubtaskResult{ID: st.ID, Name: st.Name, Points: st.Points}
+		if earns(i, make(map[int]bool)) {
+			result.Earned = st.Points
+		}
+		score += result.Earned
+		maxScore += st.Points
+		breakdown = append(breakdown, result)
+	}
+
+	return score, maxScore, breakdown
+}
+
+// Verdict summarises a run as the verdict of its first failing test, or
+// Accepted when every test passed.
+func Verdict(results []models.TestResult) string {
+	if len(results) == 0 {
+		return models.VerdictSystemError
+	}
+	for _, r := range results {
+		if !r.Passed && r.Verdict != models.VerdictSkipped {
+			return r.Verdict
+		}
+	}
+	return models.VerdictAccepted
+}
+
+// ApplyHintPenalty deducts penalty percent of the score for each hint used,
+// rounding to the nearest point. The score never drops below zero.
+func ApplyHintPenalty(score, hintsUsed, penalty int) int {
+	if hintsUsed <= 0 || penalty <= 0 {
+		return score
+	}
+	return max((score*(100-hintsUsed*penalty)+50)/100, 0)
+}
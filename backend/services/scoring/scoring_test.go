@@ -0,0 +1,216 @@
+package scoring
+
+import (
+	"reflect"
+	"testing"
+
+	"strathlearn/backend/models"
+)
+
+func testCases(ids ...string) []models.TestCase {
+	cases := make([]models.TestCase, 0, len(ids))
+	for _, id := range ids {
+		cases = append(cases, models.TestCase{ID: id})
+	}
+	return cases
+}
+
+func results(passed map[string]bool, ids ...string) []models.TestResult {
+	out := make([]models.TestResult, 0, len(ids))
+	for _, id := range ids {
+		r := models.TestResult{TestCaseID: id, Passed: passed[id], Verdict: models.VerdictAccepted}
+		if !r.Passed {
+			r.Verdict = models.VerdictWrongAnswer
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// subtaskChallenge has a small subtask, a large one that depends on it and
+// an independent bonus.
+func subtaskChallenge() models.Challenge {
+	return models.Challenge{
+		TestCases: testCases("s1", "s2", "l1", "l2", "b1"),
+		Subtasks: []models.Subtask{
+			{ID: "small", Points: 20, TestCases: []string{"s1", "s2"}},
+			{ID: "large", Points: 70, TestCases: []string{"l1", "l2"}, DependsOn: []string{"small"}},
+			{ID: "bonus", Points: 10, TestCases: []string{"b1"}},
+		},
+	}
+}
+
+func TestSubtasksDefaultToOnePointPerTest(t *testing.T) {
+	got := Subtasks(models.Challenge{TestCases: testCases("a", "b")})
+	want := []models.Subtask{
+		{ID: "a", Points: 1, TestCases: []string{"a"}},
+		{ID: "b", Points: 1, TestCases: []string{"b"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Subtasks = %+v, want %+v", got, want)
+	}
+}
+
+func TestScore(t *testing.T) {
+	ids := []string{"s1", "s2", "l1", "l2", "b1"}
+	tests := []struct {
+		name   string
+		passed map[string]bool
+		score  int
+		earned []int
+	}{
+		{
+			name:   "everything passes",
+			passed: map[string]bool{"s1": true, "s2": true, "l1": true, "l2": true, "b1": true},
+			score:  100,
+			earned: []int{20, 70, 10},
+		},
+		{
+			name:   "one failure loses the whole subtask",
+			passed: map[string]bool{"s1": true, "s2": true, "l1": true, "b1": true},
+			score:  30,
+			earned: []int{20, 0, 10},
+		},
+		{
+			name:   "dependent subtask is lost with its dependency",
+			passed: map[string]bool{"s1": true, "l1": true, "l2": true, "b1": true},
+			score:  10,
+			earned: []int{0, 0, 10},
+		},
+		{
+			name:   "nothing passes",
+			passed: map[string]bool{},
+			earned: []int{0, 0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, maxScore, breakdown := Score(subtaskChallenge(), results(tt.passed, ids...))
+			if score != tt.score || maxScore != 100 {
+				t.Errorf("Score = %d/%d, want %d/100", score, maxScore, tt.score)
+			}
+			earned := make([]int, 0, len(breakdown))
+			for _, st := range breakdown {
+				earned = append(earned, st.Earned)
+			}
+			if !reflect.DeepEqual(earned, tt.earned) {
+				t.Errorf("earned = %v, want %v", earned, tt.earned)
+			}
+		})
+	}
+}
+
+func TestScoreEdgeCases(t *testing.T) {
+	cycle := models.Challenge{
+		TestCases: testCases("a", "b"),
+		Subtasks: []models.Subtask{
+			{ID: "x", Points: 5, TestCases: []string{"a"}, DependsOn: []string{"y"}},
+			{ID: "y", Points: 5, TestCases: []string{"b"}, DependsOn: []string{"x"}},
+		},
+	}
+	if score, _, _ := Score(cycle, results(map[string]bool{"a": true, "b": true}, "a", "b")); score != 0 {
+		t.Errorf("dependency cycle scored %d, want 0", score)
+	}
+
+	empty := models.Challenge{Subtasks: []models.Subtask{{ID: "empty", Points: 5}}}
+	if score, maxScore, _ := Score(empty, nil); score != 0 || maxScore != 5 {
+		t.Errorf("subtask without tests scored %d/%d, want 0/5", score, maxScore)
+	}
+
+	plain := models.Challenge{TestCases: testCases("a", "b", "c")}
+	if score, maxScore, _ := Score(plain, results(map[string]bool{"a": true, "c": true}, "a", "b", "c")); score != 2 || maxScore != 3 {
+		t.Errorf("per-test scoring = %d/%d, want 2/3", score, maxScore)
+	}
+}
+
+func TestPlanSkipsLostSubtasks(t *testing.T) {
+	challenge := subtaskChallenge()
+	challenge.TestCases = append(challenge.TestCases, models.TestCase{ID: "shared"})
+	challenge.Subtasks[0].TestCases = append(challenge.Subtasks[0].TestCases, "shared")
+	challenge.Subtasks[2].TestCases = append(challenge.Subtasks[2].TestCases, "shared")
+
+	plan := NewPlan(challenge)
+	for _, id := range []string{"s1", "s2", "l1", "l2", "b1", "shared"} {
+		if plan.Skip(id) {
+			t.Fatalf("Skip(%s) before any failure", id)
+		}
+	}
+
+	plan.Record(models.TestResult{TestCaseID: "s1", Passed: true})
+	if plan.Skip("s2") {
+		t.Fatal("a passing test should not cause skips")
+	}
+
+	plan.Record(models.TestResult{TestCaseID: "s1", Passed: false})
+	for id, want := range map[string]bool{
+		"s2":     true,  // same subtask
+		"l1":     true,  // depends on the failed subtask
+		"b1":     false, // independent
+		"shared": false, // still worth points in bonus
+		"other":  false, // not in any subtask
+	} {
+		if got := plan.Skip(id); got != want {
+			t.Errorf("Skip(%s) = %v, want %v", id, got, want)
+		}
+	}
+
+	plan.Record(models.TestResult{TestCaseID: "b1", Passed: false})
+	if !plan.Skip("shared") {
+		t.Error("Skip(shared) after both of its subtasks were lost = false, want true")
+	}
+}
+
+func TestVerdict(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []models.TestResult
+		want    string
+	}{
+		{name: "no results", want: models.VerdictSystemError},
+		{
+			name:    "all passed",
+			results: []models.TestResult{{Passed: true}, {Passed: true}},
+			want:    models.VerdictAccepted,
+		},
+		{
+			name: "first failure wins over skips",
+			results: []models.TestResult{
+				{Passed: true},
+				SkippedResult("x"),
+				{Passed: false, Verdict: models.VerdictTimeLimit},
+				{Passed: false, Verdict: models.VerdictWrongAnswer},
+			},
+			want: models.VerdictTimeLimit,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verdict(tt.results); got != tt.want {
+				t.Errorf("Verdict = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyHintPenalty(t *testing.T) {
+	tests := []struct {
+		score, hints, penalty, want int
+	}{
+		{score: 100, hints: 0, penalty: 10, want: 100},
+		{score: 100, hints: 2, penalty: 0, want: 100},
+		{score: 100, hints: 1, penalty: 10, want: 90},
+		{score: 100, hints: 3, penalty: 25, want: 25},
+		{score: 100, hints: 5, penalty: 25, want: 0},
+		{score: 2, hints: 1, penalty: 25, want: 2},
+		{score: 2, hints: 1, penalty: 50, want: 1},
+		{score: 3, hints: 1, penalty: 50, want: 2},
+		{score: 0, hints: 2, penalty: 50, want: 0},
+	}
+	for _, tt := range tests {
+		if got := ApplyHintPenalty(tt.score, tt.hints, tt.penalty); got != tt.want {
+			t.Errorf("ApplyHintPenalty(%d, %d, %d) = %d, want %d", tt.score, tt.hints, tt.penalty, got, tt.want)
+		}
+	}
+}
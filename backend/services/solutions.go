@@ -0,0 +1,64 @@
+package services
+
+import (
+	"gorm.io/gorm"
+
+	"strathlearn/backend/db"
+)
+
+// SolutionRepository records give-ups and every time a student is shown a
+// challenge's solutions.
+type SolutionRepository interface {
+	// GiveUp records that userID gave up on a challenge, which stops it
+	// scoring for them. Giving up twice is a no-op.
+	GiveUp(userID, challengeID string) error
+	HasGivenUp(userID, challengeID string) (bool, error)
+	RecordReveal(userID, challengeID, reason string) error
+	// Reveals lists who was shown a challenge's solutions, newest first.
+	Reveals(challengeID string) ([]db.SolutionReveal, error)
+}
+
+// DBSolutionStore keeps give-ups and solution reveals in the database.
+type DBSolutionStore struct {
+	db *gorm.DB
+}
+
+func NewDBSolutionStore(conn *gorm.DB) *DBSolutionStore {
+	return &DBSolutionStore{db: conn}
+}
+
+func (s *DBSolutionStore) GiveUp(userID, challengeID string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		giveUp := db.GiveUp{UserID: userID, ChallengeID: challengeID}
+		if err := tx.Where(giveUp).FirstOrCreate(&giveUp).Error; err != nil {
+			return err
+		}
+		return refreshTotals(tx, userID)
+	})
+}
+
+func (s *DBSolutionStore) HasGivenUp(userID, challengeID string) (bool, error) {
+	return hasGivenUp(s.db, userID, challengeID)
+}
+
+func (s *DBSolutionStore) RecordReveal(userID, challengeID, reason string) error {
+	return s.db.Create(&db.SolutionReveal{
+		UserID:      userID,
+		ChallengeID: challengeID,
+		Reason:      reason,
+	}).Error
+}
+
+func (s *DBSolutionStore) Reveals(challengeID string) ([]db.SolutionReveal, error) {
+	var reveals []db.SolutionReveal
+	err := s.db.Where("challenge_id = ?", challengeID).Order("created_at DESC").Find(&reveals).Error
+	return reveals, err
+}
+
+func hasGivenUp(conn *gorm.DB, userID, challengeID string) (bool, error) {
+	var count int64
+	err := conn.Model(&db.GiveUp{}).
+		Where("user_id = ? AND challenge_id = ?", userID, challengeID).
+		Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"strathlearn/backend/db"
+	"strathlearn/backend/models"
+)
+
+// openSQLite opens an in-memory database with every migration applied. It
+// keeps a single connection, since each connection to ":memory:" gets a
+// database of its own.
+func openSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+	conn, err := db.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := conn.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Disconnect(conn) })
+
+	if _, err := db.Migrate(conn); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return conn
+}
+
+func TestSQLiteMigrations(t *testing.T) {
+	conn := openSQLite(t)
+
+	statuses, err := db.Status(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) < 5 {
+		t.Fatalf("%d migrations, want at least 5", len(statuses))
+	}
+	for i, status := range statuses {
+		if status.Version != i+1 || status.AppliedAt == nil {
+			t.Errorf("migration %04d_%s: applied at %v", status.Version, status.Name, status.AppliedAt)
+		}
+	}
+
+	if _, err := db.Rollback(conn, len(statuses)); err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	if applied, err := db.Migrate(conn); err != nil || len(applied) != len(statuses) {
+		t.Fatalf("migrating again applied %d migrations, %v", len(applied), err)
+	}
+}
+
+func TestSQLiteChallengeStore(t *testing.T) {
+	conn := openSQLite(t)
+	versions := NewChallengeVersions(conn)
+	store := NewDBChallengeStore(conn, versions)
+
+	challenge := models.Challenge{
+		ID:         "sum",
+		Title:      "Sum",
+		Difficulty: "easy",
+		TestCases:  []models.TestCase{{ID: "1", Input: "1 2", ExpectedOutput: "3"}},
+	}
+	if err := store.Create(challenge, "teacher"); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := store.Create(challenge, "teacher"); !errors.Is(err, ErrChallengeExists) {
+		t.Errorf("creating twice: error = %v, want ErrChallengeExists", err)
+	}
+	if author, err := store.AuthorOf("sum"); err != nil || author != "teacher" {
+		t.Errorf("author = %q, %v, want teacher", author, err)
+	}
+
+	if err := store.SaveTestCase("sum", models.TestCase{ID: "2", Input: "2 2", ExpectedOutput: "4"}); err != nil {
+		t.Fatalf("save test case: %v", err)
+	}
+	if err := store.SaveTestCase("sum", models.TestCase{ID: "1", Input: "1 1", ExpectedOutput: "2"}); err != nil {
+		t.Fatalf("replace test case: %v", err)
+	}
+	if err := store.DeleteTestCase("sum", "2"); err != nil {
+		t.Fatalf("delete test case: %v", err)
+	}
+	if err := store.DeleteTestCase("sum", "2"); !errors.Is(err, ErrTestCaseNotFound) {
+		t.Errorf("deleting twice: error = %v, want ErrTestCaseNotFound", err)
+	}
+
+	saved, ok := store.Get("sum")
+	if !ok || len(saved.TestCases) != 1 || saved.TestCases[0].ExpectedOutput != "2" {
+		t.Fatalf("saved challenge = %+v, %v", saved, ok)
+	}
+	// Every change records a version: create, two saves and a delete.
+	if version, err := versions.Current(saved); err != nil || version != 4 {
+		t.Errorf("current version = %d, %v, want 4", version, err)
+	}
+	if old, ok := versions.Get("sum", 1); !ok || old.TestCases[0].ExpectedOutput != "3" {
+		t.Errorf("version 1 = %+v, %v, want the created challenge", old, ok)
+	}
+
+	saved.Title = "Add two numbers"
+	if err := store.Update(saved); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if version, _ := versions.Record(saved); version != 5 {
+		t.Errorf("recording the updated challenge gave version %d, want 5", version)
+	}
+	if err := store.Delete("sum"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, ok := store.Get("sum"); ok {
+		t.Error("deleted challenge still found")
+	}
+}
+
+func TestSQLiteSubmissionAndProfileStores(t *testing.T) {
+	conn := openSQLite(t)
+	challenges := NewDBChallengeStore(conn, NewChallengeVersions(conn))
+	for _, challenge := range []models.Challenge{
+		{ID: "sum", Title: "Sum", Difficulty: "Easy"},
+		{ID: "sort", Title: "Sort", Difficulty: "hard"},
+	} {
+		if err := challenges.Create(challenge, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	submissions := NewDBSubmissionStore(conn)
+	profiles := NewDBProfileStore(conn)
+	stats := NewProfileStats(profiles, submissions, challenges)
+
+	pass := []models.TestResult{{TestCaseID: "1", Passed: true}}
+	fail := []models.TestResult{{TestCaseID: "1", Verdict: models.VerdictWrongAnswer}}
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	for i, s := range []db.Submission{
+		NewSubmission("alice", "sum", "c", "fail", 1, fail, 0, 10, 0, 0),
+		NewSubmission("alice", "sum", "c", "pass", 1, pass, 8, 10, 1, 2),
+		NewSubmission("alice", "sort", "c", "pass", 1, pass, 10, 10, 0, 0),
+		NewSubmission("bob", "sum", "c", "pass", 1, pass, 10, 10, 0, 0),
+		NewSubmission("bob", "sum", "c", "pass", 1, pass, 10, 10, 0, 0),
+		NewSubmission("carol", "sort", "c", "pass", 1, pass, 10, 10, 0, 0),
+	} {
+		s.CreatedAt = start.AddDate(0, 0, i)
+		if err := submissions.Create(&s); err != nil {
+			t.Fatalf("create submission %d: %v", i, err)
+		}
+		firstSolve, err := stats.Record(s)
+		if err != nil {
+			t.Fatalf("record submission %d: %v", i, err)
+		}
+		if want := i != 0 && i != 4; firstSolve != want {
+			t.Errorf("submission %d: first solve = %v, want %v", i, firstSolve, want)
+		}
+	}
+
+	if failed, err := submissions.FailedAttempts("alice", "sum"); err != nil || failed != 1 {
+		t.Errorf("failed attempts = %d, %v, want 1", failed, err)
+	}
+	best, err := submissions.BestScores("alice")
+	if err != nil || len(best) != 2 {
+		t.Fatalf("best scores = %+v, %v", best, err)
+	}
+	for _, score := range best {
+		if !score.Solved() {
+			t.Errorf("best score %+v not solved despite the hint penalty", score)
+		}
+	}
+
+	rows, err := submissions.Leaderboard(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []LeaderboardRow{
+		{Rank: 1, UserID: "alice", TotalScore: 18, ChallengesAttempted: 2, ChallengesCompleted: 2},
+		{Rank: 2, UserID: "bob", TotalScore: 10, ChallengesAttempted: 1, ChallengesCompleted: 1},
+		{Rank: 2, UserID: "carol", TotalScore: 10, ChallengesAttempted: 1, ChallengesCompleted: 1},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("leaderboard = %+v, want %+v", rows, want)
+	}
+	for _, row := range rows {
+		if rank, err := submissions.Rank(row.UserID); err != nil || rank != row.Rank {
+			t.Errorf("rank of %s = %d, %v, want %d", row.UserID, rank, err, row.Rank)
+		}
+	}
+	if rank, err := submissions.Rank("dave"); err != nil || rank != 0 {
+		t.Errorf("rank of a user without submissions = %d, %v, want 0", rank, err)
+	}
+
+	now := start.AddDate(0, 0, 2)
+	summary, err := stats.Summary("alice", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Rank != 1 || summary.ChallengesSolved != 2 || summary.TotalSubmissions != 3 || summary.TotalScore != 18 ||
+		summary.SolvedByDifficulty["easy"] != 1 || summary.SolvedByDifficulty["hard"] != 1 {
+		t.Errorf("alice's summary = %+v", summary)
+	}
+	if summary.CurrentStreak != 3 || summary.LongestStreak != 3 {
+		t.Errorf("alice's streaks = %d, %d, want 3, 3", summary.CurrentStreak, summary.LongestStreak)
+	}
+
+	// Rebuilding from the stored submissions gives the same stats.
+	if err := stats.Rebuild("alice"); err != nil {
+		t.Fatal(err)
+	}
+	rebuilt, err := stats.Summary("alice", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rebuilt.ChallengesSolved != summary.ChallengesSolved || rebuilt.LongestStreak != summary.LongestStreak ||
+		rebuilt.TotalScore != summary.TotalScore {
+		t.Errorf("rebuilt summary = %+v, want %+v", rebuilt, summary)
+	}
+}
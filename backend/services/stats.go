@@ -0,0 +1,152 @@
+package services
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"strathlearn/backend/db"
+	"strathlearn/backend/models"
+)
+
+// UnratedDifficulty groups solves of challenges without a difficulty.
+const UnratedDifficulty = "unrated"
+
+// ProfileStats keeps the counters on users' profiles up to date as their
+// submissions are judged.
+type ProfileStats struct {
+	profiles    ProfileRepository
+	submissions SubmissionRepository
+	challenges  ChallengeRepository
+}
+
+func NewProfileStats(profiles ProfileRepository, submissions SubmissionRepository, challenges ChallengeRepository) *ProfileStats {
+	return &ProfileStats{profiles: profiles, submissions: submissions, challenges: challenges}
+}
+
+// StatsSummary is what a user's profile shows about their progress. Rank is
+// the user's rank on the leaderboard, 0 until they attempt a challenge.
+type StatsSummary struct {
+	Rank                int            `json:"rank"`
+	ChallengesSolved    int            `json:"challengesSolved"`
+	SolvedByDifficulty  map[string]int `json:"solvedByDifficulty"`
+	CurrentStreak       int            `json:"currentStreak"`
+	LongestStreak       int            `json:"longestStreak"`
+	TotalSubmissions    int            `json:"totalSubmissions"`
+	FirstSubmission     *time.Time     `json:"firstSubmission"`
+	LastSubmission      *time.Time     `json:"lastSubmission"`
+	TotalScore          int            `json:"totalScore"`
+	ChallengesCompleted int            `json:"challengesCompleted"`
+}
+
+// Record folds a judged submission into its author's profile and reports
+// whether it was their first solve of the challenge.
+func (s *ProfileStats) Record(submission db.Submission) (bool, error) {
+	return s.profiles.RecordSubmission(submission, s.difficulty(submission.ChallengeID))
+}
+
+// Rebuild recomputes a user's profile stats from their submissions.
+func (s *ProfileStats) Rebuild(userID string) error {
+	return s.profiles.Rebuild(userID, s.difficulty)
+}
+
+func (s *ProfileStats) Summary(userID string, now time.Time) (StatsSummary, error) {
+	profile, _, err := s.profiles.Get(userID)
+	if err != nil {
+		return StatsSummary{}, err
+	}
+	solved, err := s.profiles.SolvedByDifficulty(userID)
+	if err != nil {
+		return StatsSummary{}, err
+	}
+	rank, err := s.submissions.Rank(userID)
+	if err != nil {
+		return StatsSummary{}, err
+	}
+	return StatsSummary{
+		Rank:                rank,
+		ChallengesSolved:    profile.ChallengesSolved,
+		SolvedByDifficulty:  solved,
+		CurrentStreak:       activeStreak(profile, now),
+		LongestStreak:       profile.LongestStreak,
+		TotalSubmissions:    profile.TotalSubmissions,
+		FirstSubmission:     profile.FirstSubmissionAt,
+		LastSubmission:      profile.LastSubmissionAt,
+		TotalScore:          profile.TotalScore,
+		ChallengesCompleted: profile.ChallengesCompleted,
+	}, nil
+}
+
+// Streaks returns the user's current and longest streaks of active days.
+func (s *ProfileStats) Streaks(userID string, now time.Time) (current, longest int, err error) {
+	profile, _, err := s.profiles.Get(userID)
+	if err != nil {
+		return 0, 0, err
+	}
+	return activeStreak(profile, now), profile.LongestStreak, nil
+}
+
+func (s *ProfileStats) difficulty(challengeID string) string {
+	challenge, _ := s.challenges.Get(challengeID)
+	if difficulty := strings.ToLower(strings.TrimSpace(challenge.Difficulty)); difficulty != "" {
+		return difficulty
+	}
+	return UnratedDifficulty
+}
+
+// isAccepted reports whether a submission solved its challenge. Submissions
+// recorded before verdicts were stored only carry Judge0's status code.
+func isAccepted(submission db.Submission) bool {
+	if submission.Verdict == "" {
+		return submission.StatusCode == judge0Status[models.VerdictAccepted].id
+	}
+	return submission.Verdict == models.VerdictAccepted
+}
+
+// advanceStreak extends the profile's streak of consecutive active days with
+// activity at t. Days are UTC calendar days, and activity on or before the
+// streak's last day changes nothing.
+func advanceStreak(profile *db.Profile, t time.Time) {
+	day := t.UTC().Truncate(24 * time.Hour)
+	last := profile.LastStreakDate.UTC().Truncate(24 * time.Hour)
+	if profile.Streaks > 0 && !day.After(last) {
+		return
+	}
+	if profile.Streaks > 0 && day.Equal(last.AddDate(0, 0, 1)) {
+		profile.Streaks++
+	} else {
+		profile.Streaks = 1
+	}
+	profile.LastStreakDate = day
+	profile.LongestStreak = max(profile.LongestStreak, profile.Streaks)
+}
+
+// activeStreak is the profile's streak as of now: it lapses once a whole
+// day passes without activity.
+func activeStreak(profile db.Profile, now time.Time) int {
+	today := now.UTC().Truncate(24 * time.Hour)
+	if profile.LastStreakDate.UTC().Before(today.AddDate(0, 0, -1)) {
+		return 0
+	}
+	return profile.Streaks
+}
+
+// BackfillProfileStats rebuilds every user's profile stats by replaying
+// their submissions oldest first. It is for data recorded before stats were
+// maintained, and returns the number of users rebuilt.
+func BackfillProfileStats(conn *gorm.DB, challenges ChallengeRepository) (int, error) {
+	var userIDs []string
+	err := conn.Model(&db.Submission{}).Distinct("user_id").Order("user_id").Pluck("user_id", &userIDs).Error
+	if err != nil {
+		return 0, err
+	}
+
+	stats := NewProfileStats(NewDBProfileStore(conn), NewDBSubmissionStore(conn), challenges)
+	for i, userID := range userIDs {
+		if err := stats.Rebuild(userID); err != nil {
+			return i, err
+		}
+	}
+	return len(userIDs), nil
+}
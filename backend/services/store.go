@@ -0,0 +1,250 @@
+package services
+
+import (
+	"errors"
+	"log"
+
+	"gorm.io/gorm"
+
+	"strathlearn/backend/db"
+	"strathlearn/backend/models"
+)
+
+var (
+	ErrChallengeNotFound = errors.New("challenge not found")
+	ErrChallengeExists   = errors.New("challenge already exists")
+	ErrTestCaseNotFound  = errors.New("test case not found")
+	ErrReadOnlyStore     = errors.New("challenge store is read-only")
+)
+
+// ChallengeRepository is where the handlers read and author challenges.
+type ChallengeRepository interface {
+	Get(id string) (models.Challenge, bool)
+	All() map[string]models.Challenge
+	Create(challenge models.Challenge, authorID string) error
+	// AuthorOf returns the ID of the user who created a challenge, or ""
+	// for imported challenges.
+	AuthorOf(id string) (string, error)
+	Update(challenge models.Challenge) error
+	Delete(id string) error
+	SaveTestCase(challengeID string, tc models.TestCase) error
+	DeleteTestCase(challengeID, testID string) error
+}
+
+// FileChallengeStore serves challenges loaded from disk. Files are edited
+// directly, so authoring through the API is not supported.
+type FileChallengeStore struct {
+	*ChallengeSet
+}
+
+func NewFileChallengeStore(set *ChallengeSet) *FileChallengeStore {
+	return &FileChallengeStore{ChallengeSet: set}
+}
+
+func (s *FileChallengeStore) Create(models.Challenge, string) error { return ErrReadOnlyStore }
+
+func (s *FileChallengeStore) AuthorOf(id string) (string, error) {
+	if _, ok := s.Get(id); !ok {
+		return "", ErrChallengeNotFound
+	}
+	return "", ErrReadOnlyStore
+}
+
+func (s *FileChallengeStore) Update(models.Challenge) error { return ErrReadOnlyStore }
+func (s *FileChallengeStore) Delete(string) error           { return ErrReadOnlyStore }
+
+func (s *FileChallengeStore) SaveTestCase(string, models.TestCase) error {
+	return ErrReadOnlyStore
+}
+
+func (s *FileChallengeStore) DeleteTestCase(string, string) error {
+	return ErrReadOnlyStore
+}
+
+// DBChallengeStore keeps challenges and their test cases in the database.
+type DBChallengeStore struct {
+	db       *gorm.DB
+	versions *ChallengeVersions
+}
+
+func NewDBChallengeStore(conn *gorm.DB, versions *ChallengeVersions) *DBChallengeStore {
+	return &DBChallengeStore{db: conn, versions: versions}
+}
+
+func (s *DBChallengeStore) Get(id string) (models.Challenge, bool) {
+	var challenge db.Challenge
+	err := s.db.Preload("TestCases", orderTestCases).First(&challenge, "id = ?", id).Error
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("Error loading challenge %s: %v", id, err)
+		}
+		return models.Challenge{}, false
+	}
+	return challenge.ToModel(), true
+}
+
+func (s *DBChallengeStore) All() map[string]models.Challenge {
+	var rows []db.Challenge
+	if err := s.db.Preload("TestCases", orderTestCases).Find(&rows).Error; err != nil {
+		log.Printf("Error loading challenges: %v", err)
+	}
+
+	challenges := make(map[string]models.Challenge, len(rows))
+	for _, row := range rows {
+		challenges[row.ID] = row.ToModel()
+	}
+	return challenges
+}
+
+func (s *DBChallengeStore) Create(challenge models.Challenge, authorID string) error {
+	return s.versions.transaction(s.db, func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&db.Challenge{}).Where("id = ?", challenge.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrChallengeExists
+		}
+
+		row := db.ChallengeFromModel(challenge)
+		row.AuthorID = authorID
+		if err := tx.Create(&row).Error; err != nil {
+			return err
+		}
+		return recordStoredVersion(tx, challenge.ID)
+	})
+}
+
+func (s *DBChallengeStore) AuthorOf(id string) (string, error) {
+	var row db.Challenge
+	if err := s.db.Select("id, author_id").First(&row, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrChallengeNotFound
+		}
+		return "", err
+	}
+	return row.AuthorID, nil
+}
+
+func (s *DBChallengeStore) Update(challenge models.Challenge) error {
+	return s.versions.transaction(s.db, func(tx *gorm.DB) error {
+		var existing db.Challenge
+		if err := tx.First(&existing, "id = ?", challenge.ID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrChallengeNotFound
+			}
+			return err
+		}
+
+		row := db.ChallengeFromModel(challenge)
+		row.AuthorID = existing.AuthorID
+		row.CreatedAt = existing.CreatedAt
+		testCases := row.TestCases
+		row.TestCases = nil
+
+		if err := tx.Select("*").Omit("TestCases").Save(&row).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("challenge_id = ?", challenge.ID).Delete(&db.TestCase{}).Error; err != nil {
+			return err
+		}
+		if len(testCases) > 0 {
+			if err := tx.Create(&testCases).Error; err != nil {
+				return err
+			}
+		}
+		return recordStoredVersion(tx, challenge.ID)
+	})
+}
+
+func (s *DBChallengeStore) Delete(id string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("challenge_id = ?", id).Delete(&db.TestCase{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&db.Challenge{}, "id = ?", id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrChallengeNotFound
+		}
+		return nil
+	})
+}
+
+// SaveTestCase adds a test case or replaces the one with the same ID.
+func (s *DBChallengeStore) SaveTestCase(challengeID string, tc models.TestCase) error {
+	return s.versions.transaction(s.db, func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&db.Challenge{}).Where("id = ?", challengeID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrChallengeNotFound
+		}
+
+		var existing db.TestCase
+		found := tx.Where("challenge_id = ? AND test_id = ?", challengeID, tc.ID).Limit(1).Find(&existing)
+		if found.Error != nil {
+			return found.Error
+		}
+		if found.RowsAffected > 0 {
+			row := db.TestCaseFromModel(challengeID, existing.Position, tc)
+			row.ID = existing.ID
+			if err := tx.Save(&row).Error; err != nil {
+				return err
+			}
+			return recordStoredVersion(tx, challengeID)
+		}
+
+		var position int
+		err := tx.Model(&db.TestCase{}).Where("challenge_id = ?", challengeID).
+			Select("COALESCE(MAX(position) + 1, 0)").Scan(&position).Error
+		if err != nil {
+			return err
+		}
+		row := db.TestCaseFromModel(challengeID, position, tc)
+		if err := tx.Create(&row).Error; err != nil {
+			return err
+		}
+		return recordStoredVersion(tx, challengeID)
+	})
+}
+
+func (s *DBChallengeStore) DeleteTestCase(challengeID, testID string) error {
+	return s.versions.transaction(s.db, func(tx *gorm.DB) error {
+		result := tx.Where("challenge_id = ? AND test_id = ?", challengeID, testID).Delete(&db.TestCase{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTestCaseNotFound
+		}
+		return recordStoredVersion(tx, challengeID)
+	})
+}
+
+// ImportChallenges copies challenges into the database store. Existing
+// challenges are skipped unless overwrite is set.
+func ImportChallenges(store *DBChallengeStore, challenges map[string]models.Challenge, overwrite bool) (imported, skipped int, err error) {
+	for _, challenge := range challenges {
+		err := store.Create(challenge, "")
+		if errors.Is(err, ErrChallengeExists) {
+			if !overwrite {
+				skipped++
+				continue
+			}
+			err = store.Update(challenge)
+		}
+		if err != nil {
+			return imported, skipped, err
+		}
+		imported++
+	}
+	return imported, skipped, nil
+}
+
+func orderTestCases(tx *gorm.DB) *gorm.DB {
+	return tx.Order("position ASC")
+}
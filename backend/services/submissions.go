@@ -0,0 +1,301 @@
+package services
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"strathlearn/backend/db"
+	"strathlearn/backend/models"
+	"strathlearn/backend/services/scoring"
+	"strathlearn/backend/utils"
+)
+
+// maxStoredOutput caps the output and error kept for each test result.
+const maxStoredOutput = 4096
+
+// judge0Status maps verdicts to the Judge0 status codes stored alongside
+// them, the only outcome rows recorded before verdicts carry.
+var judge0Status = map[string]struct {
+	id   int
+	desc string
+}{
+	models.VerdictAccepted:      {3, "Accepted"},
+	models.VerdictWrongAnswer:   {4, "Wrong Answer"},
+	models.VerdictTimeLimit:     {5, "Time Limit Exceeded"},
+	models.VerdictCompileError:  {6, "Compilation Error"},
+	models.VerdictRuntimeError:  {11, "Runtime Error (NZEC)"},
+	models.VerdictMemoryError:   {11, "Runtime Error (NZEC)"},
+	models.VerdictRuleViolation: {6, "Compilation Error"},
+	models.VerdictSystemError:   {13, "Internal Error"},
+}
+
+// NewSubmission builds the record of one judged submission. Score is the
+// final score, after the hint penalty.
+func NewSubmission(userID, challengeID, language, code string, version int, results []models.TestResult, score, maxScore, hintsUsed, hintPenalty int) db.Submission {
+	submission := db.Submission{
+		ChallengeID:      challengeID,
+		UserID:           userID,
+		Language:         language,
+		Code:             code,
+		Score:            score,
+		MaxScore:         maxScore,
+		ChallengeVersion: version,
+		HintsUsed:        hintsUsed,
+		HintPenalty:      hintPenalty,
+	}
+	ApplyResults(&submission, results)
+	return submission
+}
+
+// ApplyResults sets the verdict, status, resource totals and per-test
+// results of a submission from its test results.
+func ApplyResults(submission *db.Submission, results []models.TestResult) {
+	submission.Verdict = scoring.Verdict(results)
+	status, ok := judge0Status[submission.Verdict]
+	if !ok {
+		status = judge0Status[models.VerdictSystemError]
+	}
+	submission.StatusCode = status.id
+	submission.StatusDesc = status.desc
+
+	submission.Time = 0
+	submission.Memory = 0
+	submission.TestsPassed = 0
+	submission.TestsTotal = len(results)
+	submission.CompileOutput = ""
+	submission.Message = ""
+	submission.Results = make([]db.SubmissionResult, 0, len(results))
+	for i, r := range results {
+		row := db.SubmissionResultFromModel(i, r)
+		row.SubmissionID = submission.ID
+		row.Output = utils.Truncate(row.Output, maxStoredOutput)
+		row.Error = utils.Truncate(row.Error, maxStoredOutput)
+		submission.Results = append(submission.Results, row)
+
+		submission.Time += r.ExecutionTime
+		submission.Memory = max(submission.Memory, r.Memory)
+		if r.Passed {
+			submission.TestsPassed++
+		}
+		switch {
+		case r.Verdict == models.VerdictCompileError && submission.CompileOutput == "":
+			submission.CompileOutput = strings.TrimPrefix(r.Error, "Compilation error: ")
+		case !r.Passed && submission.Message == "" && r.Verdict != models.VerdictSkipped:
+			submission.Message = r.Error
+		}
+	}
+}
+
+// SubmissionRepository stores judged submissions and answers the queries
+// the profile, history and leaderboard endpoints make over them.
+type SubmissionRepository interface {
+	Create(submission *db.Submission) error
+	Get(id string) (db.Submission, bool, error)
+	// Results returns the per-test results of a submission in the order the
+	// tests ran.
+	Results(submissionID string) ([]models.TestResult, error)
+	List(q SubmissionQuery) (SubmissionPage, error)
+	// ForChallenge returns the submissions of a challenge that have code,
+	// optionally only those currently accepted.
+	ForChallenge(challengeID string, onlyAccepted bool) ([]db.Submission, error)
+	// UpdateOutcome saves a rejudged verdict, score and results.
+	UpdateOutcome(submission db.Submission) error
+	DailyActivity(userID string, from, to time.Time) ([]DailyActivity, error)
+	// FailedAttempts counts the user's judged submissions to a challenge
+	// that were not accepted.
+	FailedAttempts(userID, challengeID string) (int, error)
+	// BestScores returns the user's best score on every challenge they
+	// attempted. Challenges the user gave up on score nothing.
+	BestScores(userID string) ([]BestScore, error)
+	// Leaderboard returns the top rows of the leaderboard in rank order.
+	Leaderboard(limit int) ([]LeaderboardRow, error)
+	// Rank returns the user's rank on the leaderboard, or 0 if they have not
+	// attempted a challenge.
+	Rank(userID string) (int, error)
+}
+
+// DailyActivity counts a user's submissions to one challenge on one day.
+type DailyActivity struct {
+	Date        time.Time
+	ChallengeID string
+	Count       int
+}
+
+type BestScore struct {
+	ChallengeID string
+	BestScore   int
+	// RawScore is the best score before hint penalties, so using hints
+	// never stops a challenge counting as solved.
+	RawScore int
+	MaxScore int
+}
+
+// Solved reports whether the best score earned full marks.
+func (b BestScore) Solved() bool {
+	return b.MaxScore > 0 && b.RawScore >= b.MaxScore
+}
+
+// LeaderboardRow is one user's standing. Users are ranked by total score,
+// then by challenges completed; users tied on both share a rank.
+type LeaderboardRow struct {
+	Rank                int
+	UserID              string
+	TotalScore          int
+	ChallengesAttempted int
+	ChallengesCompleted int
+}
+
+// DBSubmissionStore keeps submissions in the database.
+type DBSubmissionStore struct {
+	db *gorm.DB
+}
+
+func NewDBSubmissionStore(conn *gorm.DB) *DBSubmissionStore {
+	return &DBSubmissionStore{db: conn}
+}
+
+func (s *DBSubmissionStore) Create(submission *db.Submission) error {
+	return s.db.Create(submission).Error
+}
+
+func (s *DBSubmissionStore) Get(id string) (db.Submission, bool, error) {
+	var submission db.Submission
+	result := s.db.Where("id = ?", id).Limit(1).Find(&submission)
+	return submission, result.RowsAffected > 0, result.Error
+}
+
+func (s *DBSubmissionStore) Results(submissionID string) ([]models.TestResult, error) {
+	var rows []db.SubmissionResult
+	if err := s.db.Where("submission_id = ?", submissionID).Order("position ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	results := make([]models.TestResult, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, row.ToModel())
+	}
+	return results, nil
+}
+
+func (s *DBSubmissionStore) ForChallenge(challengeID string, onlyAccepted bool) ([]db.Submission, error) {
+	query := s.db.Where("challenge_id = ? AND code <> ''", challengeID)
+	if onlyAccepted {
+		query = query.Where("verdict = ?", models.VerdictAccepted)
+	}
+	var submissions []db.Submission
+	err := query.Find(&submissions).Error
+	return submissions, err
+}
+
+func (s *DBSubmissionStore) UpdateOutcome(submission db.Submission) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&submission).
+			Select("verdict", "status_code", "status_desc", "time", "memory", "tests_passed", "tests_total",
+				"compile_output", "message", "score", "hint_penalty", "max_score", "challenge_version").
+			Updates(&submission).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("submission_id = ?", submission.ID).Delete(&db.SubmissionResult{}).Error; err != nil {
+			return err
+		}
+		if len(submission.Results) == 0 {
+			return nil
+		}
+		for i := range submission.Results {
+			submission.Results[i].SubmissionID = submission.ID
+		}
+		return tx.Create(&submission.Results).Error
+	})
+}
+
+func (s *DBSubmissionStore) DailyActivity(userID string, from, to time.Time) ([]DailyActivity, error) {
+	// DATE() is a date on Postgres but text on SQLite, so read it as text.
+	var rows []struct {
+		Date        string
+		ChallengeID string
+		Count       int
+	}
+	err := s.db.Model(&db.Submission{}).
+		Select("DATE(created_at) as date, COUNT(*) as count, challenge_id").
+		Where("user_id = ? AND created_at BETWEEN ? AND ?", userID, from, to).
+		Group("DATE(created_at), challenge_id").
+		Order("date ASC").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	activity := make([]DailyActivity, 0, len(rows))
+	for _, row := range rows {
+		date, err := time.Parse("2006-01-02", row.Date[:min(len(row.Date), 10)])
+		if err != nil {
+			return nil, err
+		}
+		activity = append(activity, DailyActivity{Date: date, ChallengeID: row.ChallengeID, Count: row.Count})
+	}
+	return activity, nil
+}
+
+func (s *DBSubmissionStore) FailedAttempts(userID, challengeID string) (int, error) {
+	var failed int64
+	err := s.db.Model(&db.Submission{}).
+		Where("user_id = ? AND challenge_id = ? AND verdict NOT IN ?", userID, challengeID,
+			[]string{"", models.VerdictAccepted}).
+		Count(&failed).Error
+	return int(failed), err
+}
+
+func (s *DBSubmissionStore) BestScores(userID string) ([]BestScore, error) {
+	var scores []BestScore
+	err := s.db.Table("(?) AS best", bestScores(s.db).Where("user_id = ?", userID)).
+		Select("challenge_id, best_score, raw_score, max_score").
+		Scan(&scores).Error
+	return scores, err
+}
+
+func (s *DBSubmissionStore) Leaderboard(limit int) ([]LeaderboardRow, error) {
+	var rows []LeaderboardRow
+	err := s.db.Table("(?) AS leaderboard", leaderboard(s.db)).
+		Order("rank ASC, user_id ASC").
+		Limit(limit).
+		Scan(&rows).Error
+	return rows, err
+}
+
+func (s *DBSubmissionStore) Rank(userID string) (int, error) {
+	var row LeaderboardRow
+	err := s.db.Table("(?) AS leaderboard", leaderboard(s.db)).
+		Where("user_id = ?", userID).
+		Limit(1).
+		Scan(&row).Error
+	return row.Rank, err
+}
+
+// leaderboard totals every user's best scores and ranks them, so the
+// leaderboard and profiles agree on each user's rank.
+func leaderboard(conn *gorm.DB) *gorm.DB {
+	const completed = "COUNT(CASE WHEN max_score > 0 AND raw_score >= max_score THEN 1 END)"
+	return conn.Table("(?) AS best", bestScores(conn)).
+		Select("user_id, SUM(best_score) AS total_score, COUNT(*) AS challenges_attempted, " +
+			completed + " AS challenges_completed, " +
+			"RANK() OVER (ORDER BY SUM(best_score) DESC, " + completed + " DESC) AS rank").
+		Group("user_id")
+}
+
+// bestScores selects each user's highest score on every challenge they
+// attempted, so retries never lower a student's standing, along with the
+// highest score before hint penalties. Challenges the user gave up on score
+// nothing.
+func bestScores(conn *gorm.DB) *gorm.DB {
+	const gaveUp = "EXISTS (SELECT 1 FROM give_ups " +
+		"WHERE give_ups.user_id = submissions.user_id AND give_ups.challenge_id = submissions.challenge_id)"
+	return conn.Model(&db.Submission{}).
+		Select("user_id, challenge_id, " +
+			"CASE WHEN " + gaveUp + " THEN 0 ELSE MAX(score) END AS best_score, " +
+			"CASE WHEN " + gaveUp + " THEN 0 ELSE MAX(score + hint_penalty) END AS raw_score, " +
+			"MAX(max_score) AS max_score").
+		Where("challenge_id <> ''").
+		Group("user_id, challenge_id")
+}
@@ -0,0 +1,356 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"strathlearn/backend/models"
+)
+
+// Track files live alongside challenges and are named <name>.track.json,
+// <name>.track.yaml or <name>.track.yml.
+var trackSuffixes = []string{".track.json", ".track.yaml", ".track.yml"}
+
+func isTrackFile(name string) bool {
+	lower := strings.ToLower(name)
+	for _, suffix := range trackSuffixes {
+		if strings.HasSuffix(lower, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadTracks reads every track file under roots and returns the files that
+// could not be loaded alongside the tracks that could.
+func LoadTracks(roots ...string) (map[string]models.Track, map[string]error) {
+	tracks := make(map[string]models.Track)
+	failed := make(map[string]error)
+
+	files, errs := discoverFiles(roots, func(path string) bool {
+		return isTrackFile(filepath.Base(path))
+	})
+	for _, err := range errs {
+		log.Printf("Error reading track directory: %v", err)
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file.Path)
+		if err == nil && !strings.EqualFold(filepath.Ext(file.Path), ".json") {
+			data, err = yamlToJSON(data)
+		}
+		if err != nil {
+			failed[file.Path] = err
+			continue
+		}
+
+		var track models.Track
+		if err := json.Unmarshal(data, &track); err != nil {
+			failed[file.Path] = err
+			continue
+		}
+		track.FilePath = file.Path
+		if track.ID == "" {
+			name := filepath.Base(file.Path)
+			track.ID = name[:len(name)-len(trackSuffix(name))]
+		}
+		if existing, ok := tracks[track.ID]; ok {
+			failed[file.Path] = fmt.Errorf("duplicate track ID %s, also used by %s", track.ID, existing.FilePath)
+			continue
+		}
+		tracks[track.ID] = track
+	}
+	return tracks, failed
+}
+
+func trackSuffix(name string) string {
+	lower := strings.ToLower(name)
+	for _, suffix := range trackSuffixes {
+		if strings.HasSuffix(lower, suffix) {
+			return suffix
+		}
+	}
+	return ""
+}
+
+// ValidateTracks reports references to unknown challenges, modules and
+// tracks, and prerequisite cycles that would keep a module locked forever.
+func ValidateTracks(tracks map[string]models.Track, challenges map[string]models.Challenge) []LintIssue {
+	var issues []LintIssue
+	add := func(track models.Track, field, format string, args ...interface{}) {
+		issues = append(issues, LintIssue{
+			File:     track.FilePath,
+			Severity: SeverityError,
+			Field:    field,
+			Message:  fmt.Sprintf("track %s: ", track.ID) + fmt.Sprintf(format, args...),
+		})
+	}
+
+	for _, track := range sortedTracks(tracks) {
+		for _, dep := range track.Prerequisites {
+			if _, ok := tracks[dep]; !ok {
+				add(track, "prerequisites", "unknown prerequisite track %q", dep)
+			}
+		}
+
+		modules := make(map[string]models.Module, len(track.Modules))
+		for i, module := range track.Modules {
+			field := fmt.Sprintf("modules[%d]", i)
+			if _, dup := modules[module.ID]; dup || module.ID == "" {
+				add(track, field+".id", "missing or duplicate module ID %q", module.ID)
+			}
+			modules[module.ID] = module
+		}
+		for i, module := range track.Modules {
+			field := fmt.Sprintf("modules[%d]", i)
+			for _, id := range module.Challenges {
+				if _, ok := challenges[id]; !ok {
+					add(track, field+".challenges", "unknown challenge %q", id)
+				}
+			}
+			for _, dep := range module.Prerequisites {
+				if _, ok := modules[dep]; !ok {
+					add(track, field+".prerequisites", "unknown prerequisite module %q", dep)
+				}
+			}
+			if module.RequiredSolved > len(module.Challenges) {
+				add(track, field+".requiredSolved", "requires %d solved but has %d challenges",
+					module.RequiredSolved, len(module.Challenges))
+			}
+		}
+		if cycle := moduleCycle(track); cycle != "" {
+			add(track, "modules", "prerequisite cycle through module %s", cycle)
+		}
+	}
+	if cycle := trackCycle(tracks); cycle != "" {
+		add(tracks[cycle], "prerequisites", "prerequisite cycle through track %s", cycle)
+	}
+	return issues
+}
+
+func moduleCycle(track models.Track) string {
+	deps := make(map[string][]string, len(track.Modules))
+	for i, module := range track.Modules {
+		deps[module.ID] = modulePrerequisites(track, i)
+	}
+	return findCycle(deps)
+}
+
+func trackCycle(tracks map[string]models.Track) string {
+	deps := make(map[string][]string, len(tracks))
+	for id, track := range tracks {
+		deps[id] = track.Prerequisites
+	}
+	return findCycle(deps)
+}
+
+func findCycle(deps map[string][]string) string {
+	ids := make([]string, 0, len(deps))
+	for id := range deps {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	// state is 1 while a node is on the current path and 2 once finished.
+	state := make(map[string]int, len(deps))
+	var visit func(id string) string
+	visit = func(id string) string {
+		switch state[id] {
+		case 1:
+			return id
+		case 2:
+			return ""
+		}
+		state[id] = 1
+		for _, dep := range deps[id] {
+			if cycle := visit(dep); cycle != "" {
+				return cycle
+			}
+		}
+		state[id] = 2
+		return ""
+	}
+	for _, id := range ids {
+		if cycle := visit(id); cycle != "" {
+			return cycle
+		}
+	}
+	return ""
+}
+
+// modulePrerequisites returns the modules that must be complete before the
+// i-th module of a track unlocks.
+func modulePrerequisites(track models.Track, i int) []string {
+	deps := track.Modules[i].Prerequisites
+	if track.Sequential && i > 0 {
+		deps = append([]string{track.Modules[i-1].ID}, deps...)
+	}
+	return deps
+}
+
+// TrackProgress evaluates the unlock rules of a track for a user who has
+// solved the given challenges.
+func TrackProgress(track models.Track, tracks map[string]models.Track, challenges map[string]models.Challenge, solved map[string]bool) models.TrackProgress {
+	progress := models.TrackProgress{
+		ID:            track.ID,
+		Title:         track.Title,
+		Description:   track.Description,
+		Prerequisites: track.Prerequisites,
+		Unlocked:      true,
+		Completed:     trackCompleted(track, solved),
+	}
+	for _, dep := range track.Prerequisites {
+		if prereq, ok := tracks[dep]; !ok || !trackCompleted(prereq, solved) {
+			progress.Unlocked = false
+		}
+	}
+
+	counted := make(map[string]bool)
+	completed := make(map[string]bool, len(track.Modules))
+	for _, module := range track.Modules {
+		completed[module.ID] = moduleCompleted(module, solved)
+	}
+
+	for i, module := range track.Modules {
+		mp := models.ModuleProgress{
+			ID:            module.ID,
+			Title:         module.Title,
+			Description:   module.Description,
+			Prerequisites: modulePrerequisites(track, i),
+			Unlocked:      progress.Unlocked,
+			Completed:     completed[module.ID],
+			Required:      moduleRequired(module),
+			Challenges:    make([]models.ChallengeProgress, 0, len(module.Challenges)),
+		}
+		for _, dep := range mp.Prerequisites {
+			if !completed[dep] {
+				mp.Unlocked = false
+			}
+		}
+		for _, id := range module.Challenges {
+			if solved[id] {
+				mp.Solved++
+			}
+			// Challenges that are missing from the store are reported by
+			// ValidateTracks; students just don't see them.
+			challenge, ok := challenges[id]
+			if !ok {
+				continue
+			}
+			mp.Challenges = append(mp.Challenges, models.ChallengeProgress{
+				ID:         id,
+				Title:      challenge.Title,
+				Difficulty: challenge.Difficulty,
+				Solved:     solved[id],
+			})
+			if !counted[id] {
+				counted[id] = true
+				progress.Total++
+				if solved[id] {
+					progress.Solved++
+				}
+			}
+		}
+		progress.Modules = append(progress.Modules, mp)
+	}
+	return progress
+}
+
+func moduleRequired(module models.Module) int {
+	if module.RequiredSolved > 0 && module.RequiredSolved < len(module.Challenges) {
+		return module.RequiredSolved
+	}
+	return len(module.Challenges)
+}
+
+func moduleCompleted(module models.Module, solved map[string]bool) bool {
+	count := 0
+	for _, id := range module.Challenges {
+		if solved[id] {
+			count++
+		}
+	}
+	return count >= moduleRequired(module)
+}
+
+func trackCompleted(track models.Track, solved map[string]bool) bool {
+	for _, module := range track.Modules {
+		if !moduleCompleted(module, solved) {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedTracks(tracks map[string]models.Track) []models.Track {
+	sorted := make([]models.Track, 0, len(tracks))
+	for _, track := range tracks {
+		sorted = append(sorted, track)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Order != sorted[j].Order {
+			return sorted[i].Order < sorted[j].Order
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+	return sorted
+}
+
+// TrackSet holds the loaded tracks and is swapped wholesale on reload.
+type TrackSet struct {
+	mu     sync.RWMutex
+	tracks map[string]models.Track
+}
+
+func NewTrackSet(tracks map[string]models.Track) *TrackSet {
+	return &TrackSet{tracks: tracks}
+}
+
+func (s *TrackSet) Get(id string) (models.Track, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	track, ok := s.tracks[id]
+	return track, ok
+}
+
+// All returns every track keyed by ID.
+func (s *TrackSet) All() map[string]models.Track {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	all := make(map[string]models.Track, len(s.tracks))
+	for id, track := range s.tracks {
+		all[id] = track
+	}
+	return all
+}
+
+// Sorted returns the tracks in display order.
+func (s *TrackSet) Sorted() []models.Track {
+	return sortedTracks(s.All())
+}
+
+func (s *TrackSet) Replace(tracks map[string]models.Track) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tracks = tracks
+}
+
+// ReloadTracks re-reads the track files under roots into the set and logs
+// any problems with them.
+func ReloadTracks(set *TrackSet, roots []string, challenges map[string]models.Challenge) {
+	tracks, failed := LoadTracks(roots...)
+	for path, err := range failed {
+		log.Printf("Error loading track %s: %v", path, err)
+	}
+	for _, issue := range ValidateTracks(tracks, challenges) {
+		log.Printf("Track lint %s", issue)
+	}
+	set.Replace(tracks)
+	log.Printf("Loaded %d tracks", len(tracks))
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"strathlearn/backend/models"
+	"strathlearn/backend/services/rules"
+	"strathlearn/backend/services/runner"
+)
+
+// SolutionReport is the outcome of running one reference solution against
+// every test case of its challenge.
+type SolutionReport struct {
+	ChallengeID string
+	Solution    int
+	TimeLimit   int
+	MaxTime     float64
+	Failures    []string
+	Results     []models.TestResult
+}
+
+// Passed reports whether the solution passed every test.
+func (r SolutionReport) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// Headroom is the fraction of the time limit used by the slowest test, or
+// zero when the challenge has no time limit.
+func (r SolutionReport) Headroom() float64 {
+	if r.TimeLimit <= 0 {
+		return 0
+	}
+	return r.MaxTime / float64(r.TimeLimit)
+}
+
+// VerifyChallenge runs every reference solution of a challenge against all of
+// its test cases, including hidden ones.
+func VerifyChallenge(challenge models.Challenge, r runner.CodeRunner) []SolutionReport {
+	if len(challenge.Solutions) == 0 {
+		return []SolutionReport{{
+			ChallengeID: challenge.ID,
+			TimeLimit:   challenge.TimeLimit,
+			Failures:    []string{"challenge has no reference solutions"},
+		}}
+	}
+
+	// Every test must run, so subtask early exit is disabled.
+	full := challenge
+	full.Subtasks = nil
+
+	reports := make([]SolutionReport, 0, len(challenge.Solutions))
+	for i, solution := range challenge.Solutions {
+		report := SolutionReport{
+			ChallengeID: challenge.ID,
+			Solution:    i + 1,
+			TimeLimit:   challenge.TimeLimit,
+		}
+
+		if violations := rules.Check(solution, challenge.Rules); len(violations) > 0 {
+			report.Failures = append(report.Failures, rules.Explain(violations))
+		}
+
+		if len(challenge.TestCases) == 0 {
+			report.Failures = append(report.Failures, "challenge has no test cases")
+			reports = append(reports, report)
+			continue
+		}
+
+		report.Results = r.RunTests(solution, full)
+		seen := make(map[string]bool, len(report.Results))
+		for _, result := range report.Results {
+			seen[result.TestCaseID] = true
+			report.MaxTime = max(report.MaxTime, result.ExecutionTime)
+			if !result.Passed {
+				report.Failures = append(report.Failures,
+					fmt.Sprintf("%s: %s", result.TestCaseID, strings.TrimSpace(result.Error)))
+			}
+		}
+		for _, tc := range challenge.TestCases {
+			if !seen[tc.ID] && len(report.Results) > 1 {
+				report.Failures = append(report.Failures, fmt.Sprintf("%s: no result", tc.ID))
+			}
+		}
+
+		reports = append(reports, report)
+	}
+
+	return reports
+}
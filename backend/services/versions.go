@@ -0,0 +1,140 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"gorm.io/gorm"
+
+	"strathlearn/backend/db"
+	"strathlearn/backend/models"
+)
+
+// VersionRepository records and looks up immutable snapshots of challenges.
+type VersionRepository interface {
+	// Record returns the version number of the challenge's current content,
+	// creating a new version if the content has changed since the last one.
+	Record(challenge models.Challenge) (int, error)
+	// Current returns the latest recorded version of the challenge without
+	// hashing it, recording one only if the challenge has none yet.
+	Current(challenge models.Challenge) (int, error)
+	// Get returns a recorded version of a challenge.
+	Get(challengeID string, version int) (models.Challenge, bool)
+}
+
+// ChallengeVersions records immutable snapshots of challenges so submissions
+// can say exactly which tests they were judged against.
+type ChallengeVersions struct {
+	db *gorm.DB
+	mu sync.Mutex
+}
+
+func NewChallengeVersions(conn *gorm.DB) *ChallengeVersions {
+	return &ChallengeVersions{db: conn}
+}
+
+func (v *ChallengeVersions) Record(challenge models.Challenge) (int, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return recordChallengeVersion(v.db, challenge)
+}
+
+// Current relies on versions being recorded whenever challenges are loaded,
+// reloaded or saved, so judging does not hash the challenge every time.
+func (v *ChallengeVersions) Current(challenge models.Challenge) (int, error) {
+	var latest db.ChallengeVersion
+	result := v.db.Select("version").Where("challenge_id = ?", challenge.ID).
+		Order("version DESC").Limit(1).Find(&latest)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return latest.Version, nil
+	}
+	return v.Record(challenge)
+}
+
+// transaction runs fn in a transaction on conn while holding the lock that
+// Record takes, so versions written by the challenge store cannot race
+// Record for the next version number.
+func (v *ChallengeVersions) transaction(conn *gorm.DB, fn func(tx *gorm.DB) error) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return conn.Transaction(fn)
+}
+
+// RecordAll records versions for every challenge, returning the first error.
+func (v *ChallengeVersions) RecordAll(challenges map[string]models.Challenge) error {
+	var firstErr error
+	for _, challenge := range challenges {
+		if _, err := v.Record(challenge); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (v *ChallengeVersions) Get(challengeID string, version int) (models.Challenge, bool) {
+	var row db.ChallengeVersion
+	result := v.db.Where("challenge_id = ? AND version = ?", challengeID, version).Limit(1).Find(&row)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return models.Challenge{}, false
+	}
+	return row.Content, true
+}
+
+func recordChallengeVersion(tx *gorm.DB, challenge models.Challenge) (int, error) {
+	hash, err := challengeHash(challenge)
+	if err != nil {
+		return 0, err
+	}
+
+	var latest db.ChallengeVersion
+	result := tx.Where("challenge_id = ?", challenge.ID).Order("version DESC").Limit(1).Find(&latest)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected > 0 && latest.Hash == hash {
+		return latest.Version, nil
+	}
+
+	challenge.FilePath = ""
+	row := db.ChallengeVersion{
+		ChallengeID: challenge.ID,
+		Version:     latest.Version + 1,
+		Hash:        hash,
+		Content:     challenge,
+	}
+	if err := tx.Create(&row).Error; err != nil {
+		return 0, err
+	}
+	return row.Version, nil
+}
+
+// recordStoredVersion snapshots a challenge as it currently is in the
+// challenges table. Callers run it inside ChallengeVersions.transaction.
+func recordStoredVersion(tx *gorm.DB, challengeID string) error {
+	var row db.Challenge
+	if err := tx.Preload("TestCases", orderTestCases).First(&row, "id = ?", challengeID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrChallengeNotFound
+		}
+		return err
+	}
+	_, err := recordChallengeVersion(tx, row.ToModel())
+	return err
+}
+
+// challengeHash fingerprints the judged content of a challenge. FilePath is
+// not serialised, so moving a file does not create a new version.
+func challengeHash(challenge models.Challenge) (string, error) {
+	data, err := json.Marshal(challenge)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}